@@ -1,5 +1,3 @@
 module github.com/midbel/linewriter
 
-go 1.15
-
-require github.com/midbel/sizefmt v0.0.0-20190516041901-a77d0f4718c9
+go 1.18
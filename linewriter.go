@@ -1,13 +1,14 @@
 package linewriter
 
 import (
-	"encoding/hex"
+	"bytes"
+	"errors"
 	"io"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
-
-	"github.com/midbel/sizefmt"
 )
 
 type Flag uint64
@@ -41,12 +42,65 @@ const (
 	Microsecond
 	SizeSI
 	SizeIEC
+	Truncate
+	TruncateLeft
+	TruncateMiddle
+	WithGrouping
+	Engineering
+	Significant
+	SIPrefix
+	SizeBits
+	SizeLong
+	WithUnitSpace
+)
+
+var (
+	ErrOverflow   = errors.New("linewriter: buffer overflow")
+	ErrFlag       = errors.New("linewriter: invalid flag combination")
+	ErrTimeFormat = errors.New("linewriter: invalid time format")
+	ErrKind       = errors.New("linewriter: value does not match column kind")
+	ErrRecord     = errors.New("linewriter: record does not match schema")
+	ErrBits       = errors.New("linewriter: invalid bit width")
+)
+
+const (
+	alignFlags    = AlignLeft | AlignRight | AlignCenter
+	baseFlags     = Hex | Octal | Binary | Decimal
+	boolFlags     = YesNo | OnOff | TrueFalse | OneZero
+	durationFlags = Second | Millisecond | Microsecond
+	sizeFlags     = SizeSI | SizeIEC
+	truncateFlags = Truncate | TruncateLeft | TruncateMiddle
 )
 
+type mode uint8
+
+const (
+	modeText mode = iota
+	modeCSV
+	modeDSV
+	modeJSON
+	modeLogfmt
+	modeMarkdown
+)
+
+type cell struct {
+	data   []byte
+	width  int
+	flag   Flag
+	number bool
+}
+
 type Option func(*Writer)
 
 const DefaultFlags = AlignRight | Text | Second | TrueFalse | Decimal | Float | SizeIEC
 
+const hexDigits = "0123456789abcdef"
+
+var (
+	baseGroupSep = []byte("_")
+	baseGroups   = []int{4}
+)
+
 type Writer struct {
 	buffer []byte
 	tmp    []byte
@@ -56,6 +110,32 @@ type Writer struct {
 	dontaddsep  bool
 	ignorenosep bool
 
+	growable bool
+	strict   bool
+	err      error
+
+	mode mode
+
+	schema []Column
+	names  []string
+	field  int
+
+	cells   []cell
+	headers []string
+	headed  bool
+	scratch []byte
+	trimmed []byte
+
+	ellipsis []byte
+	marker   rune
+	autoexp  bool
+	groupsep []byte
+	groups   []int
+	locale   *Locale
+	sizeprec int
+
+	label     []byte
+	suffix    []byte
 	padding   []byte
 	separator []byte
 	newline   []byte
@@ -65,9 +145,13 @@ type Writer struct {
 
 func NewWriter(size int, options ...Option) *Writer {
 	w := Writer{
-		buffer: make([]byte, size),
-		tmp:    make([]byte, 0, 512),
-		flags:  DefaultFlags,
+		buffer:   make([]byte, size),
+		tmp:      make([]byte, 0, 512),
+		flags:    DefaultFlags,
+		ellipsis: []byte("…"),
+		groupsep: []byte(","),
+		groups:   []int{3},
+		sizeprec: -1,
 	}
 	for i := 0; i < len(options); i++ {
 		options[i](&w)
@@ -75,7 +159,17 @@ func NewWriter(size int, options ...Option) *Writer {
 	if len(w.newline) == 0 {
 		w.newline = []byte("\n")
 	}
+	if len(w.names) == 0 {
+		for _, c := range w.schema {
+			w.names = append(w.names, c.Name)
+		}
+	}
+	if w.mode == modeMarkdown && len(w.headers) == 0 {
+		w.headers = append(w.headers, w.names...)
+	}
 	w.Reset()
+	w.write(w.label)
+	w.base = w.offset
 	return &w
 }
 
@@ -88,16 +182,116 @@ func AsCSV(quoted bool) Option {
 			w.flags |= WithQuote
 		}
 		w.ignorenosep = true
+		w.mode = modeCSV
+	}
+}
+
+func AsTSV() Option {
+	return AsDelimited('\t')
+}
+
+func AsDelimited(sep rune, options ...Option) Option {
+	return func(w *Writer) {
+		w.separator = []byte(string(sep))
+		w.flags |= NoPadding | NoSpace
+		w.ignorenosep = true
+		w.mode = modeDSV
+		for i := 0; i < len(options); i++ {
+			options[i](w)
+		}
+	}
+}
+
+func AsJSON(names ...string) Option {
+	return func(w *Writer) {
+		w.label = append(w.label[:0], '{')
+		w.suffix = append(w.suffix[:0], '}')
+		w.separator = append(w.separator[:0], ',')
+		w.flags |= NoPadding | NoSpace
+		w.names = append(w.names[:0], names...)
+		w.mode = modeJSON
+	}
+}
+
+func AsLogfmt(keys ...string) Option {
+	return func(w *Writer) {
+		w.separator = append(w.separator[:0], ' ')
+		w.flags |= NoPadding | NoSpace
+		w.names = append(w.names[:0], keys...)
+		w.mode = modeLogfmt
+	}
+}
+
+func AsMarkdown(headers ...string) Option {
+	return func(w *Writer) {
+		w.label = append(w.label[:0], '|')
+		w.suffix = append(w.suffix[:0], '|')
+		w.separator = append(w.separator[:0], '|')
+		w.padding = append(w.padding[:0], ' ')
+		w.headers = append(w.headers[:0], headers...)
+		w.ignorenosep = true
+		w.mode = modeMarkdown
 	}
 }
 
 func WithLabel(p string) Option {
 	return func(w *Writer) {
 		str := []byte(p)
-		if n := len(str) - 1; str[n] == ' ' {
+		if n := len(str) - 1; n >= 0 && str[n] == ' ' {
 			str = str[:n]
 		}
-		w.base = copy(w.buffer, str)
+		w.label = str
+	}
+}
+
+func WithGrowableBuffer() Option {
+	return func(w *Writer) {
+		w.growable = true
+	}
+}
+
+func WithStrictBuffer() Option {
+	return func(w *Writer) {
+		w.strict = true
+	}
+}
+
+func WithEllipsis(str string) Option {
+	return func(w *Writer) {
+		w.ellipsis = append(w.ellipsis[:0], str...)
+	}
+}
+
+func WithOverflowMarker(marker rune) Option {
+	return func(w *Writer) {
+		w.marker = marker
+	}
+}
+
+func WithAutoScientific() Option {
+	return func(w *Writer) {
+		w.autoexp = true
+	}
+}
+
+func WithSizePrecision(prec int) Option {
+	return func(w *Writer) {
+		w.sizeprec = prec
+	}
+}
+
+func WithGroupSeparator(sep string, sizes ...int) Option {
+	return func(w *Writer) {
+		w.groupsep = append(w.groupsep[:0], sep...)
+		var groups []int
+		for _, n := range sizes {
+			if n > 0 {
+				groups = append(groups, n)
+			}
+		}
+		if len(groups) > 0 {
+			w.groups = groups
+		}
 	}
 }
 
@@ -128,16 +322,27 @@ func WithCRLF() Option {
 func (w *Writer) Reset() {
 	n := len(w.buffer)
 	if w.offset > 0 {
-		n = w.offset
+		n = w.offset + len(w.suffix) + len(w.newline)
+	}
+	if n > len(w.buffer) {
+		n = len(w.buffer)
 	}
 	for i := w.base; i < n; i++ {
 		w.buffer[i] = ' '
 	}
 	w.offset = w.base
+	w.field = 0
+	w.cells = w.cells[:0]
+	w.err = nil
+}
+
+func (w *Writer) Err() error {
+	return w.err
 }
 
 func (w *Writer) Bytes() []byte {
-	return w.buffer[:w.offset]
+	line := w.line()
+	return line[:len(line)-len(w.newline)]
 }
 
 func (w *Writer) String() string {
@@ -148,12 +353,25 @@ func (w *Writer) Read(bs []byte) (int, error) {
 	if w.offset == 0 {
 		return w.offset, io.EOF
 	}
-	if len(bs) < w.offset {
+	if w.refuse() {
+		err := w.err
+		w.Reset()
+		return 0, err
+	}
+	var (
+		head = w.prelude()
+		line = w.line()
+	)
+	if len(bs) < len(head)+len(line) {
 		return 0, io.ErrShortBuffer
 	}
-	n := copy(bs, append(w.buffer[:w.offset], w.newline...))
+	n := copy(bs, head)
+	n += copy(bs[n:], line)
+	w.headed = w.headed || len(head) > 0
+
+	err := w.err
 	w.Reset()
-	return n, nil
+	return n, err
 }
 
 func (w *Writer) WriteTo(ws io.Writer) (int64, error) {
@@ -161,32 +379,112 @@ func (w *Writer) WriteTo(ws io.Writer) (int64, error) {
 	if w.offset == 0 || w.offset == w.base {
 		return 0, io.EOF
 	}
-	n, err := ws.Write(append(w.buffer[:w.offset], w.newline...))
+	if w.refuse() {
+		return 0, w.err
+	}
+	var c int
+	if head := w.prelude(); len(head) > 0 {
+		n, err := ws.Write(head)
+		if err != nil {
+			return int64(n), err
+		}
+		c, w.headed = n, true
+	}
+	n, err := ws.Write(w.line())
 	if err == nil {
 		err = io.EOF
 	}
-	return int64(n), err
+	if err == io.EOF && w.err != nil {
+		err = w.err
+	}
+	return int64(c + n), err
+}
+
+// refuse reports whether the current line can not be written. A line
+// truncated by a fixed buffer is still written, and its ErrOverflow is
+// returned instead of io.EOF, unless the buffer is strict.
+func (w *Writer) refuse() bool {
+	return w.err != nil && (w.err != ErrOverflow || w.strict)
+}
+
+func (w *Writer) prelude() []byte {
+	if w.mode != modeMarkdown || w.headed || len(w.headers) == 0 {
+		return nil
+	}
+	var (
+		head  = NewWriter(0, WithGrowableBuffer(), AsMarkdown())
+		align = NewWriter(0, WithGrowableBuffer(), AsMarkdown())
+		rule  []byte
+	)
+	for i := 0; i < len(w.cells) || i < len(w.headers); i++ {
+		var c cell
+		if i < len(w.cells) {
+			c = w.cells[i]
+		}
+		if i < len(w.headers) {
+			head.AppendString(w.headers[i], c.width, c.flag&alignFlags)
+		} else {
+			head.AppendString("", c.width, c.flag&alignFlags)
+		}
+		if c.width < 3 {
+			c.width = 3
+		}
+		rule = rule[:0]
+		for j := 0; j < c.width; j++ {
+			rule = append(rule, '-')
+		}
+		switch c.flag & alignFlags {
+		case AlignLeft:
+			rule[0] = ':'
+		case AlignRight:
+			rule[len(rule)-1] = ':'
+		case AlignCenter:
+			rule[0], rule[len(rule)-1] = ':', ':'
+		}
+		align.AppendBytes(rule, c.width, AlignLeft)
+	}
+	return append(head.line(), align.line()...)
+}
+
+func (w *Writer) line() []byte {
+	n := len(w.suffix) + len(w.newline)
+	if w.offset+n > len(w.buffer) && w.growable {
+		w.grow(n)
+	}
+	if w.offset+n > len(w.buffer) {
+		line := append(w.buffer[:w.offset:w.offset], w.suffix...)
+		return append(line, w.newline...)
+	}
+	copy(w.buffer[w.offset:], w.suffix)
+	copy(w.buffer[w.offset+len(w.suffix):], w.newline)
+	return w.buffer[:w.offset+n]
 }
 
 func (w *Writer) AppendSeparator(n int) {
+	if w.isKeyed() {
+		return
+	}
 	for i := 0; i < n; i++ {
-		w.offset += copy(w.buffer[w.offset:], w.separator)
+		w.write(w.separator)
 	}
 	w.dontaddsep = n > 1
 }
 
 func (w *Writer) AppendString(str string, width int, flag Flag) {
-	flag = flag &^ Hex
-	w.AppendBytes([]byte(str), width, flag|Text)
+	flag = flag&^Hex | Text
+	w.appendLeft(flag)
+	w.tmp = append(w.tmp, str...)
+	w.appendRight(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
 }
 
 func (w *Writer) AppendBytes(bs []byte, width int, flag Flag) {
 	w.appendLeft(flag)
 
 	if set := flag & Hex; set != 0 {
-		data := make([]byte, hex.EncodedLen(len(bs)))
-		hex.Encode(data, bs)
-		w.tmp = append(w.tmp, data...)
+		for _, b := range bs {
+			w.tmp = append(w.tmp, hexDigits[b>>4], hexDigits[b&0x0f])
+		}
 	} else {
 		w.tmp = append(w.tmp, bs...)
 	}
@@ -195,16 +493,28 @@ func (w *Writer) AppendBytes(bs []byte, width int, flag Flag) {
 }
 
 func (w *Writer) AppendTime(t time.Time, format string, flag Flag) {
+	w.appendTime(t, format, 0, flag)
+}
+
+func (w *Writer) appendTime(t time.Time, format string, width int, flag Flag) {
 	w.appendLeft(flag)
+	if !isTimeLayout(format) {
+		w.setError(ErrTimeFormat)
+	}
 
-	w.tmp = t.AppendFormat(w.tmp, format)
+	if w.locale != nil {
+		w.appendLocalTime(t, format)
+	} else {
+		w.tmp = t.AppendFormat(w.tmp, format)
+	}
 
-	w.appendRight(w.tmp, len(w.tmp), flag)
+	w.appendRight(w.tmp, width, flag)
 	w.tmp = w.tmp[:0]
 }
 
 func (w *Writer) AppendDuration(d time.Duration, width int, flag Flag) {
 	w.appendLeft(flag)
+	flag = w.checkFlag(flag, durationFlags)
 
 	if d == 0 {
 		w.tmp = append(w.tmp, '0')
@@ -230,24 +540,22 @@ func (w *Writer) AppendDuration(d time.Duration, width int, flag Flag) {
 
 func (w *Writer) AppendBool(b bool, width int, flag Flag) {
 	w.appendLeft(flag)
+	flag = w.checkFlag(flag, boolFlags)
 
-	var tval, fval []byte
-	if set := flag & YesNo; set != 0 {
-		tval, fval = []byte("yes"), []byte("no")
-	} else if set := flag & OnOff; set != 0 {
-		tval, fval = []byte("on"), []byte("off")
-	} else if set := flag & OneZero; set != 0 {
-		tval, fval = []byte("1"), []byte("0")
-	} else {
-		tval, fval = []byte("true"), []byte("false")
+	if w.mode == modeJSON {
+		w.tmp = strconv.AppendBool(w.tmp, b)
+		w.write(w.tmp)
+		w.tmp = w.tmp[:0]
+		return
 	}
-	var data []byte
+	tval, fval := w.boolWords(flag)
 	if b {
-		data = tval
+		w.tmp = append(w.tmp, tval...)
 	} else {
-		data = fval
+		w.tmp = append(w.tmp, fval...)
 	}
-	w.appendRight(data, width, flag)
+	w.appendRight(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
 }
 
 func (w *Writer) AppendPercent(v float64, width, prec int, flag Flag) {
@@ -263,75 +571,227 @@ func (w *Writer) AppendFloat(v float64, width, prec int, flag Flag) {
 	} else if set := flag & Float; set != 0 {
 		format = 'f'
 	}
-	w.tmp = strconv.AppendFloat(w.tmp, v, format, prec, 64)
-	if set, i := flag&WithZero, len(w.tmp)-1; set == 0 && i > 0 {
-		for i >= 0 && w.tmp[i] == '0' {
-			i--
+	w.formatFloat(v, format, prec, flag)
+	if w.autoexp && format != 'e' && w.isOverflow(w.tmp, width, flag) {
+		p := prec
+		if p < 0 {
+			p = 16
 		}
-		if i > 0 && w.tmp[i] != '.' {
-			i++
+		for ; p >= 0 && w.isOverflow(w.tmp, width, flag); p-- {
+			w.tmp = w.tmp[:0]
+			w.formatFloat(v, 'e', p, flag)
 		}
-		w.tmp = w.tmp[:i]
+	}
+	w.appendNumber(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
+}
+
+func (w *Writer) formatFloat(v float64, format byte, prec int, flag Flag) {
+	if set := flag & WithSign; set != 0 && !math.Signbit(v) && !math.IsInf(v, 1) {
+		w.tmp = append(w.tmp, '+')
+	}
+	if set := flag & Engineering; set != 0 {
+		w.formatEngineering(v, prec, flag)
+	} else if set := flag & Significant; set != 0 {
+		w.formatSignificant(v, format, prec, flag)
+	} else {
+		w.tmp = strconv.AppendFloat(w.tmp, v, format, prec, 64)
+		w.adjustFloat(flag)
 	}
 	if set := flag & Percent; set != 0 {
-		w.tmp = append(w.tmp, '%')
+		w.appendPercent()
 	}
-	w.appendRight(w.tmp, width, flag)
-	w.tmp = w.tmp[:0]
+}
+
+func (w *Writer) adjustFloat(flag Flag) {
+	if set := flag & WithZero; set == 0 {
+		w.tmp = trimFraction(w.tmp)
+	}
+	w.localizeDecimal()
+	if w.isGrouped(flag) {
+		w.groupFloat(flag)
+	}
+}
+
+func (w *Writer) groupFloat(flag Flag) {
+	i := 0
+	if i < len(w.tmp) && (w.tmp[i] == '-' || w.tmp[i] == '+') {
+		i++
+	}
+	j := i
+	for j < len(w.tmp) && w.tmp[j] >= '0' && w.tmp[j] <= '9' {
+		j++
+	}
+	if j-i <= groupSize(w.groups, 0) {
+		return
+	}
+	w.scratch = append(w.scratch[:0], w.tmp...)
+	w.tmp = w.tmp[:i]
+	w.appendDigits(w.scratch[i:j], flag)
+	w.tmp = append(w.tmp, w.scratch[j:]...)
 }
 
 func (w *Writer) AppendSize(v int64, width int, flag Flag) {
-	var size string
-	if z := float64(v); isSizeIEC(w.flags, flag) {
-		size = sizefmt.Format(z, sizefmt.IEC)
-	} else {
-		size = sizefmt.Format(z, sizefmt.SI)
+	w.appendLeft(flag)
+	flag = w.checkFlag(flag, sizeFlags)
+	if isSizeIEC(w.flags, flag) {
+		flag |= SizeIEC
 	}
-	w.AppendString(size, width, flag)
+	w.tmp = appendSize(w.tmp, v, w.sizePrecision(2), flag)
+	w.localizeDecimal()
+	w.appendNumber(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
 }
 
 func (w *Writer) AppendInt(v int64, width int, flag Flag) {
 	w.appendLeft(flag)
+	flag = w.checkFlag(flag, baseFlags)
 
-	base := w.prepareNumber(flag, v > 0)
-	if set := flag & WithZero; set != 0 {
-		for i := len(w.tmp); i < width; i++ {
-			w.tmp = append(w.tmp, '0')
-		}
+	u := uint64(v)
+	if v < 0 {
+		u = -u
+	}
+	w.formatInteger(u, v < 0, width, flag)
+
+	w.appendNumber(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
+}
+
+func (w *Writer) AppendUint(v uint64, width int, flag Flag) {
+	w.appendLeft(flag)
+	flag = w.checkFlag(flag, baseFlags)
+
+	w.formatInteger(v, false, width, flag)
+
+	w.appendNumber(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
+}
+
+func (w *Writer) AppendIntBits(v int64, bits, width int, flag Flag) {
+	if bits <= 0 || bits > 64 {
+		w.setError(ErrBits)
+		w.AppendInt(v, width, flag)
+		return
 	}
-	tmp := make([]byte, 0, 8)
-	tmp = strconv.AppendInt(tmp, v, base)
+	w.appendLeft(flag)
+	flag = w.checkFlag(flag, baseFlags)
 
-	if n := len(w.tmp); n == 0 || n < len(tmp) || flag&WithZero == 0 {
-		w.tmp = append(w.tmp, tmp...)
+	var (
+		shift = uint(64 - bits)
+		u     = uint64(v) << shift >> shift
+	)
+	if base := w.prepareNumber(flag&^WithSign, true); base == 10 {
+		if v = int64(u<<shift) >> shift; v < 0 {
+			u = -uint64(v)
+		}
+		w.formatInteger(u, v < 0, width, flag)
 	} else {
-		copy(w.tmp[n-len(tmp):], tmp)
+		var (
+			digits [64]byte
+			ds     = strconv.AppendUint(digits[:0], u, base)
+			size   = bits
+		)
+		switch base {
+		case 16:
+			size = (bits + 3) / 4
+		case 8:
+			size = (bits + 2) / 3
+		}
+		if n := size - len(ds); n > 0 {
+			ds = ds[:size]
+			copy(ds[n:], ds)
+			for i := 0; i < n; i++ {
+				ds[i] = '0'
+			}
+		}
+		w.padDigits(ds, width, flag)
 	}
 
-	w.appendRight(w.tmp, width, flag)
+	w.appendNumber(w.tmp, width, flag)
 	w.tmp = w.tmp[:0]
 }
 
-func (w *Writer) AppendUint(v uint64, width int, flag Flag) {
-	w.appendLeft(flag)
+func (w *Writer) formatInteger(v uint64, negative bool, width int, flag Flag) {
+	if negative {
+		w.tmp = append(w.tmp, '-')
+	}
+	base := w.prepareNumber(flag, !negative)
 
-	base := w.prepareNumber(flag, v > 0)
-	if set := flag & WithZero; set != 0 {
-		for i := len(w.tmp); i < width; i++ {
+	var digits [64]byte
+	w.padDigits(strconv.AppendUint(digits[:0], v, base), width, flag)
+}
+
+func (w *Writer) padDigits(ds []byte, width int, flag Flag) {
+	if n := len(w.buffer) - w.offset; !w.growable && width > n {
+		width = n
+	}
+	// like printf, zeros are only used to pad numbers that are not
+	// aligned to the left.
+	if flag&(WithZero|AlignLeft) == WithZero {
+		for n := displayWidth(w.tmp) + w.groupedWidth(len(ds), flag); n < width; n++ {
 			w.tmp = append(w.tmp, '0')
 		}
 	}
-	tmp := make([]byte, 0, 8)
-	tmp = strconv.AppendUint(tmp, v, base)
+	w.appendDigits(ds, flag)
+}
 
-	if n := len(w.tmp); n == 0 || n < len(tmp) || flag&WithZero == 0 {
-		w.tmp = append(w.tmp, tmp...)
-	} else {
-		copy(w.tmp[n-len(tmp):], tmp)
+func (w *Writer) appendDigits(digits []byte, flag Flag) {
+	if !w.isGrouped(flag) {
+		w.tmp = append(w.tmp, digits...)
+		return
+	}
+	var (
+		sep, groups = w.grouping(flag)
+		n           = len(digits)
+		k           int
+	)
+	for size := groupSize(groups, k); n > size; size = groupSize(groups, k) {
+		n -= size
+		k++
 	}
+	w.tmp = append(w.tmp, digits[:n]...)
+	for k--; k >= 0; k-- {
+		w.tmp = append(w.tmp, sep...)
+		w.tmp = append(w.tmp, digits[n:n+groupSize(groups, k)]...)
+		n += groupSize(groups, k)
+	}
+}
 
-	w.appendRight(w.tmp, width, flag)
-	w.tmp = w.tmp[:0]
+func (w *Writer) groupedWidth(n int, flag Flag) int {
+	if !w.isGrouped(flag) {
+		return n
+	}
+	var (
+		sep, groups = w.grouping(flag)
+		width       = n
+	)
+	for k := 0; n > groupSize(groups, k); k++ {
+		n -= groupSize(groups, k)
+		width += displayWidth(sep)
+	}
+	return width
+}
+
+func (w *Writer) isGrouped(flag Flag) bool {
+	set := flag & WithGrouping
+	return set != 0 && len(w.groups) > 0 && w.mode != modeJSON
+}
+
+// grouping gives the separator and the sizes of the groups of digits.
+// Hexadecimal, octal and binary digits are not grouped like decimal ones
+// but by four with an underscore, as in Go literals.
+func (w *Writer) grouping(flag Flag) ([]byte, []int) {
+	if set := flag & (Hex | Octal | Binary); set != 0 {
+		return baseGroupSep, baseGroups
+	}
+	return w.groupsep, w.groups
+}
+
+func groupSize(groups []int, k int) int {
+	if k < len(groups) {
+		return groups[k]
+	}
+	return groups[len(groups)-1]
 }
 
 func (w *Writer) appendMillis(ns int64, flag Flag) {
@@ -442,7 +902,29 @@ func skipZeros(tmp []byte) int {
 }
 
 func (w *Writer) appendRight(data []byte, width int, flag Flag) {
-	size := len(data)
+	flag = overrideFlag(flag, alignFlags)
+	if set := flag & truncateFlags; set != 0 && width > 0 {
+		data = w.truncate(data, width, set)
+	}
+	switch w.mode {
+	case modeCSV:
+		w.appendCSV(data, isWithQuote(w.flags, flag))
+		return
+	case modeDSV:
+		w.appendDSV(data)
+		return
+	case modeJSON:
+		w.writeByte('"')
+		w.appendEscaped(data)
+		w.writeByte('"')
+		return
+	case modeLogfmt:
+		w.appendLogfmt(data)
+		return
+	case modeMarkdown:
+		data = w.escapeMarkdown(data)
+	}
+	size := displayWidth(data)
 	if size > width {
 		width = size
 	}
@@ -450,44 +932,364 @@ func (w *Writer) appendRight(data []byte, width int, flag Flag) {
 	var padleft, padright int
 	if isWithSpace(w.flags, flag) {
 		if set := flag & AlignRight; set != 0 {
-			padleft = width - utf8.RuneCount(data)
+			padleft = width - size
 		} else if set := flag & AlignCenter; set != 0 {
-			padleft = (width - utf8.RuneCount(data)) / 2
+			padleft = (width - size) / 2
 			padright = padleft
 
 			if c := padleft + padright + size; c < width {
 				padright += width - c
 			}
 		} else {
-			padright = width - utf8.RuneCount(data)
+			padright = width - size
 		}
 	} else {
 		if isWithQuote(w.flags, flag) {
-			w.buffer[w.offset] = '"'
-			w.offset++
+			w.writeByte('"')
 		}
 	}
 
-	n := copy(w.buffer[w.offset+padleft:], data)
-	w.offset += n
+	if w.mode == modeMarkdown {
+		w.cells = append(w.cells, cell{
+			width: padleft + size + padright,
+			flag:  flag,
+		})
+	}
+
+	w.skip(padleft)
+	w.write(data)
 	if isWithSpace(w.flags, flag) {
-		w.offset += padleft + padright
+		w.skip(padright)
 	} else {
 		if isWithQuote(w.flags, flag) {
-			w.buffer[w.offset] = '"'
-			w.offset++
+			w.writeByte('"')
 		}
 	}
 
 	if isWithPadding(w.flags, flag) {
-		w.offset += copy(w.buffer[w.offset:], w.padding)
+		w.write(w.padding)
+	}
+}
+
+func (w *Writer) appendCSV(data []byte, quoted bool) {
+	if !quoted {
+		quoted = bytes.ContainsAny(data, "\"\r\n") || bytes.Contains(data, w.separator)
+	}
+	if !quoted {
+		w.write(data)
+		return
+	}
+	w.writeByte('"')
+	for {
+		i := bytes.IndexByte(data, '"')
+		if i < 0 {
+			break
+		}
+		w.write(data[:i+1])
+		w.writeByte('"')
+		data = data[i+1:]
+	}
+	w.write(data)
+	w.writeByte('"')
+}
+
+func (w *Writer) appendNumber(data []byte, width int, flag Flag) {
+	if w.mode == modeJSON && isNumber(data) {
+		w.write(data)
+		return
+	}
+	if w.marker != 0 && w.isOverflow(data, width, flag) {
+		w.trimmed = w.trimmed[:0]
+		for n, z := 0, runeWidth(w.marker); n+z <= width; n += z {
+			w.trimmed = append(w.trimmed, string(w.marker)...)
+		}
+		data = w.trimmed
+	}
+	w.appendRight(data, width, flag)
+}
+
+func (w *Writer) isOverflow(data []byte, width int, flag Flag) bool {
+	return width > 0 && isWithSpace(w.flags, flag) && displayWidth(data) > width
+}
+
+func (w *Writer) appendEscaped(data []byte) {
+	const hexdigits = "0123456789abcdef"
+
+	var j int
+	for i := 0; i < len(data); {
+		if c := data[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			w.write(data[j:i])
+			w.writeByte('\\')
+			switch c {
+			case '"', '\\':
+				w.writeByte(c)
+			case '\n':
+				w.writeByte('n')
+			case '\r':
+				w.writeByte('r')
+			case '\t':
+				w.writeByte('t')
+			default:
+				w.write([]byte{'u', '0', '0', hexdigits[c>>4], hexdigits[c&0xF]})
+			}
+			i++
+			j = i
+			continue
+		}
+		r, n := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && n == 1 {
+			w.write(data[j:i])
+			w.write([]byte(`\ufffd`))
+			j = i + n
+		}
+		i += n
+	}
+	w.write(data[j:])
+}
+
+func (w *Writer) truncate(data []byte, width int, flag Flag) []byte {
+	if displayWidth(data) <= width {
+		return data
+	}
+	ellipsis := w.ellipsis
+	if n := displayWidth(ellipsis); n < width {
+		width -= n
+	} else {
+		ellipsis = nil
+	}
+	w.trimmed = w.trimmed[:0]
+	switch flag {
+	case TruncateLeft:
+		w.trimmed = append(w.trimmed, ellipsis...)
+		w.trimmed = append(w.trimmed, cutLeft(data, width)...)
+	case TruncateMiddle:
+		left := cut(data, (width+1)/2)
+		w.trimmed = append(w.trimmed, left...)
+		w.trimmed = append(w.trimmed, ellipsis...)
+		w.trimmed = append(w.trimmed, cutLeft(data, width-displayWidth(left))...)
+	default:
+		w.trimmed = append(w.trimmed, cut(data, width)...)
+		w.trimmed = append(w.trimmed, ellipsis...)
+	}
+	return w.trimmed
+}
+
+func (w *Writer) escapeMarkdown(data []byte) []byte {
+	w.scratch = w.scratch[:0]
+	for _, c := range data {
+		switch c {
+		case '|':
+			w.scratch = append(w.scratch, '\\', c)
+		case '\r', '\n':
+			w.scratch = append(w.scratch, ' ')
+		default:
+			w.scratch = append(w.scratch, c)
+		}
 	}
+	return w.scratch
+}
+
+func (w *Writer) appendLogfmt(data []byte) {
+	quoted := !utf8.Valid(data)
+	for i := 0; !quoted && i < len(data); i++ {
+		c := data[i]
+		quoted = c <= ' ' || c == '=' || c == '"' || c == 0x7f
+	}
+	if !quoted {
+		w.write(data)
+		return
+	}
+	w.writeByte('"')
+	w.appendEscaped(data)
+	w.writeByte('"')
+}
+
+func (w *Writer) appendDSV(data []byte) {
+	var j int
+	for i := 0; i < len(data); {
+		var (
+			esc byte
+			n   = 1
+		)
+		switch data[i] {
+		case '\\':
+			esc = '\\'
+		case '\t':
+			esc = 't'
+		case '\n':
+			esc = 'n'
+		case '\r':
+			esc = 'r'
+		default:
+			if len(w.separator) == 0 || !bytes.HasPrefix(data[i:], w.separator) {
+				i++
+				continue
+			}
+			n = len(w.separator)
+		}
+		w.write(data[j:i])
+		w.writeByte('\\')
+		if esc != 0 {
+			w.writeByte(esc)
+		} else {
+			w.write(data[i : i+n])
+		}
+		i += n
+		j = i
+	}
+	w.write(data[j:])
+}
+
+func (w *Writer) write(bs []byte) {
+	n := w.available(len(bs))
+	w.offset += copy(w.buffer[w.offset:w.offset+n], bs)
+}
+
+func (w *Writer) writeByte(b byte) {
+	if w.available(1) == 1 {
+		w.buffer[w.offset] = b
+		w.offset++
+	}
+}
+
+func (w *Writer) skip(n int) {
+	if n <= 0 {
+		return
+	}
+	n = w.available(n)
+	for i := 0; i < n; i++ {
+		w.buffer[w.offset+i] = ' '
+	}
+	w.offset += n
+}
+
+func (w *Writer) available(n int) int {
+	if w.offset+n <= len(w.buffer) {
+		return n
+	}
+	if w.growable {
+		w.grow(n)
+		return n
+	}
+	w.setError(ErrOverflow)
+	return len(w.buffer) - w.offset
+}
+
+func (w *Writer) setError(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *Writer) checkFlag(flag Flag, groups ...Flag) Flag {
+	for _, g := range groups {
+		flag = overrideFlag(flag, g)
+		if set := flag & g; set&(set-1) != 0 {
+			w.setError(ErrFlag)
+		}
+	}
+	return flag
+}
+
+// overrideFlag lets a bit given on top of DefaultFlags replace the default
+// bit of the same group, so that DefaultFlags|Hex formats in hexadecimal.
+func overrideFlag(flag, group Flag) Flag {
+	if set := flag & group; set&(set-1) != 0 && set&^DefaultFlags != 0 {
+		flag &^= group & DefaultFlags
+	}
+	return flag
+}
+
+func (w *Writer) grow(n int) {
+	size := 2*len(w.buffer) + n
+	buf := make([]byte, size)
+	copy(buf, w.buffer)
+	for i := len(w.buffer); i < size; i++ {
+		buf[i] = ' '
+	}
+	w.buffer = buf
+}
+
+func trimFraction(tmp []byte) []byte {
+	exp := bytes.IndexAny(tmp, "eE")
+	if exp < 0 {
+		exp = len(tmp)
+	}
+	dot := bytes.IndexByte(tmp[:exp], '.')
+	if dot < 0 {
+		return tmp
+	}
+	i := exp
+	for i > dot+1 && tmp[i-1] == '0' {
+		i--
+	}
+	if i == dot+1 {
+		i = dot
+	}
+	return append(tmp[:i], tmp[exp:]...)
+}
+
+func isNumber(data []byte) bool {
+	digits := func(i int) int {
+		for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+			i++
+		}
+		return i
+	}
+	var i int
+	if i < len(data) && data[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(data) && data[i] == '0':
+		i++
+	case i < len(data) && data[i] >= '1' && data[i] <= '9':
+		i = digits(i)
+	default:
+		return false
+	}
+	if i < len(data) && data[i] == '.' {
+		j := digits(i + 1)
+		if j == i+1 {
+			return false
+		}
+		i = j
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		j := digits(i)
+		if j == i {
+			return false
+		}
+		i = j
+	}
+	return i == len(data)
+}
+
+func isTimeLayout(format string) bool {
+	if strings.ContainsAny(format, "1234567") {
+		return true
+	}
+	for _, s := range []string{"Jan", "Mon", "MST", "PM", "pm"} {
+		if strings.Contains(format, s) {
+			return true
+		}
+	}
+	return false
 }
 
 func isSizeIEC(def, giv Flag) bool {
-	d := def & SizeIEC
-	g := giv & SizeIEC
-	return g > 0 || d > 0
+	if set := giv & sizeFlags; set != 0 {
+		return set&SizeIEC != 0
+	}
+	return overrideFlag(def, sizeFlags)&SizeIEC != 0
 }
 
 func isWithPrefix(def, giv Flag) bool {
@@ -515,22 +1317,63 @@ func isWithPadding(def, giv Flag) bool {
 }
 
 func (w *Writer) appendLeft(flag Flag) {
-	if w.offset > w.base {
-		var n int
+	w.checkFlag(flag, alignFlags, truncateFlags)
+	if w.isKeyed() {
+		if w.field > 0 {
+			w.write(w.separator)
+		}
+		w.appendName()
+		return
+	}
+	if w.offset > w.base || (w.field > 0 && w.isDelimited()) {
 		if w.ignorenosep {
-			n = copy(w.buffer[w.offset:], w.separator)
+			w.write(w.separator)
 		} else if set := flag & NoSeparator; set == 0 {
 			if !w.dontaddsep {
-				n = copy(w.buffer[w.offset:], w.separator)
+				w.write(w.separator)
 			} else {
 				w.dontaddsep = !w.dontaddsep
 			}
 		}
-		w.offset += n
 	}
+	w.field++
 	if isWithPadding(w.flags, flag) {
-		w.offset += copy(w.buffer[w.offset:], w.padding)
+		w.write(w.padding)
+	}
+}
+
+func (w *Writer) isKeyed() bool {
+	return w.mode == modeJSON || w.mode == modeLogfmt
+}
+
+// isDelimited reports whether empty fields still need their separator
+// for the line to keep its number of fields.
+func (w *Writer) isDelimited() bool {
+	return w.mode == modeCSV || w.mode == modeDSV
+}
+
+func (w *Writer) appendName() {
+	if w.field < len(w.names) {
+		w.tmp = append(w.tmp, w.names[w.field]...)
+	} else {
+		w.tmp = strconv.AppendInt(w.tmp, int64(w.field+1), 10)
 	}
+	if w.mode == modeJSON {
+		w.writeByte('"')
+		w.appendEscaped(w.tmp)
+		w.writeByte('"')
+		w.writeByte(':')
+	} else {
+		for i, c := range w.tmp {
+			if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+				w.tmp[i] = '_'
+			}
+		}
+		w.write(w.tmp)
+		w.writeByte('=')
+	}
+	w.tmp = w.tmp[:0]
+	w.field++
 }
 
 func (w *Writer) prepareNumber(flag Flag, positive bool) int {
@@ -2,10 +2,17 @@ package linewriter
 
 import (
 	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 var defaults = []Option{
@@ -72,6 +79,261 @@ func ExampleAsCSV() {
 	// "1","0001","playback","44","off"
 }
 
+func TestAsCSV(t *testing.T) {
+	records := [][]string{
+		{"playback", "1", "0001"},
+		{"hello, world", "say \"hi\"", "\"\""},
+		{"multi\nline", "", "carriage\rreturn"},
+		{" leading", "trailing ", ",", "\n"},
+		{"", "x"},
+		{"", "", "y"},
+	}
+	for _, quoted := range []bool{false, true} {
+		var buf bytes.Buffer
+
+		w := NewWriter(256, AsCSV(quoted))
+		for _, rs := range records {
+			for _, r := range rs {
+				w.AppendString(r, 0, AlignLeft)
+			}
+			if _, err := w.WriteTo(&buf); err != io.EOF {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		}
+		r := csv.NewReader(&buf)
+		r.FieldsPerRecord = -1
+		got, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("quoted(%t): fail to read csv: %s", quoted, err)
+			continue
+		}
+		if len(got) != len(records) {
+			t.Errorf("quoted(%t): want %d records, got %d", quoted, len(records), len(got))
+			continue
+		}
+		for i := range records {
+			if want, got := fmt.Sprintf("%q", records[i]), fmt.Sprintf("%q", got[i]); want != got {
+				t.Errorf("quoted(%t): record %d: want %s, got %s", quoted, i+1, want, got)
+			}
+		}
+	}
+
+	w := NewWriter(256, AsCSV(false))
+	w.AppendString("say \"hi\"", 0, AlignLeft)
+	w.AppendString("a,b", 0, AlignLeft)
+	w.AppendString("plain", 0, AlignLeft)
+	if want, got := `"say ""hi""","a,b",plain`, w.String(); want != got {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestEmptyLeadingField(t *testing.T) {
+	data := []struct {
+		Name   string
+		Option Option
+		Want   string
+	}{
+		{Name: "csv", Option: AsCSV(false), Want: ",a"},
+		{Name: "tsv", Option: AsTSV(), Want: "\ta"},
+		{Name: "text", Option: WithSeparator([]byte("|")), Want: "a"},
+	}
+	for _, d := range data {
+		w := NewWriter(256, d.Option, WithFlag(DefaultFlags|NoPadding))
+		w.AppendString("", 0, AlignLeft)
+		w.AppendString("a", 0, AlignLeft)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%s: want %q, got %q", d.Name, d.Want, got)
+		}
+	}
+}
+
+func ExampleAsTSV() {
+	w := NewWriter(256, AsTSV())
+	w.AppendUint(1, 4, AlignRight)
+	w.AppendString("play\tback", 10, AlignLeft)
+	w.AppendString("multi\nline", 10, AlignLeft)
+	w.AppendString(`C:\data`, 10, AlignLeft)
+
+	fmt.Println(w.String())
+	// Output:
+	// 1	play\tback	multi\nline	C:\\data
+}
+
+func TestAsDelimited(t *testing.T) {
+	data := []struct {
+		Sep   rune
+		Value string
+		Want  string
+	}{
+		{Sep: '|', Value: "playback", Want: "playback|playback"},
+		{Sep: '|', Value: "play|back", Want: `play\|back|play\|back`},
+		{Sep: '|', Value: "play\tback", Want: `play\tback|play\tback`},
+		{Sep: ';', Value: "a;b\r\n", Want: `a\;b\r\n|a\;b\r\n`},
+		{Sep: '¦', Value: "a¦b", Want: `a\¦b|a\¦b`},
+	}
+	for i, d := range data {
+		w := NewWriter(256, AsDelimited(d.Sep, WithCRLF()))
+		w.AppendString(d.Value, 10, AlignRight)
+		w.AppendString(d.Value, 10, AlignRight|NoSeparator)
+
+		want := strings.Replace(d.Want, "|", string(d.Sep), 1) + "\r\n"
+		var buf bytes.Buffer
+		if _, err := w.WriteTo(&buf); err != io.EOF {
+			t.Errorf("%d: unexpected error: %s", i+1, err)
+			continue
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("%d: want %q, got %q", i+1, want, got)
+		}
+	}
+}
+
+func ExampleAsJSON() {
+	w := NewWriter(256, AsJSON("id", "name", "active", "elapsed"))
+	w.AppendUint(1, 4, AlignRight)
+	w.AppendString("playback", 10, AlignLeft)
+	w.AppendBool(false, 3, AlignCenter|OnOff)
+	w.AppendDuration(17*time.Hour+31*time.Minute, 10, AlignRight)
+	w.AppendFloat(0.9845, 10, 2, Float)
+
+	fmt.Println(w.String())
+	// Output:
+	// {"id":1,"name":"playback","active":false,"elapsed":"17h31m00s","5":0.98}
+}
+
+func TestAsJSON(t *testing.T) {
+	when := time.Date(2019, 6, 11, 12, 25, 43, 0, time.UTC)
+
+	w := NewWriter(256, AsJSON("int", "uint", "hex", "float", "percent", "string", "bool", "time", "duration", "size", "sign"))
+	w.AppendInt(-42, 10, AlignRight)
+	w.AppendUint(42, 10, AlignRight)
+	w.AppendUint(42, 10, AlignRight|Hex|WithPrefix)
+	w.AppendFloat(1.5e-7, 10, -1, Scientific)
+	w.AppendPercent(0.5, 10, 2, AlignRight)
+	w.AppendString("say \"hi\"\n\tto\\ \x01 \xff €", 10, AlignRight)
+	w.AppendBool(true, 10, AlignRight|YesNo)
+	w.AppendSeparator(2)
+	w.AppendTime(when, time.RFC3339, AlignRight)
+	w.AppendDuration(time.Second, 10, AlignRight)
+	w.AppendSize(1024, 10, AlignRight)
+	w.AppendInt(42, 10, AlignRight|WithSign)
+
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != io.EOF {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("}\n")) {
+		t.Fatalf("line not terminated: %q", buf.String())
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("fail to decode %q: %s", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"int":      float64(-42),
+		"uint":     float64(42),
+		"hex":      "0x2a",
+		"float":    1.5e-7,
+		"percent":  "50%",
+		"string":   "say \"hi\"\n\tto\\ \x01 \ufffd €",
+		"bool":     true,
+		"time":     when.Format(time.RFC3339),
+		"duration": "1s",
+		"size":     "1K",
+		"sign":     "+42",
+	}
+	if len(got) != len(want) {
+		t.Errorf("want %d fields, got %d", len(want), len(got))
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: want %#v, got %#v", k, v, got[k])
+		}
+	}
+}
+
+func ExampleAsLogfmt() {
+	w := NewWriter(256, AsLogfmt("level", "msg", "cached", "elapsed", "size"))
+	w.AppendString("info", 5, AlignLeft)
+	w.AppendString("request done", 10, AlignLeft)
+	w.AppendBool(false, 3, OnOff)
+	w.AppendDuration(1452320*time.Nanosecond, 10, AlignRight)
+	w.AppendSize(1<<20, 10, AlignRight)
+	w.AppendUint(404, 3, AlignRight)
+
+	fmt.Println(w.String())
+	// Output:
+	// level=info msg="request done" cached=off elapsed=1.45232ms size=1M 6=404
+}
+
+func TestAsLogfmt(t *testing.T) {
+	data := []struct {
+		Key   string
+		Value string
+		Want  string
+	}{
+		{Key: "msg", Value: "playback", Want: "msg=playback"},
+		{Key: "msg", Value: "", Want: "msg="},
+		{Key: "msg", Value: "a=b", Want: `msg="a=b"`},
+		{Key: "msg", Value: `say "hi"`, Want: `msg="say \"hi\""`},
+		{Key: "msg", Value: "multi\nline", Want: `msg="multi\nline"`},
+		{Key: "msg", Value: "\xff", Want: `msg="\ufffd"`},
+		{Key: "user name", Value: "é", Want: "user_name=é"},
+		{Key: "a=b", Value: "c", Want: "a_b=c"},
+	}
+	for i, d := range data {
+		w := NewWriter(256, AsLogfmt(d.Key))
+		w.AppendString(d.Value, 10, AlignRight)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %s, got %s", i+1, d.Want, got)
+		}
+	}
+}
+
+func ExampleAsMarkdown() {
+	w := NewWriter(256, AsMarkdown("id", "name", "state", "elapsed"))
+	w.AppendUint(1, 4, AlignRight)
+	w.AppendString("playback", 10, AlignLeft)
+	w.AppendBool(false, 5, AlignCenter|OnOff)
+	w.AppendDuration(17*time.Hour+31*time.Minute, 10, AlignRight)
+	w.WriteTo(os.Stdout)
+
+	w.AppendUint(2, 4, AlignRight)
+	w.AppendString("rec|ord", 10, AlignLeft)
+	w.AppendBool(true, 5, AlignCenter|OnOff)
+	w.AppendDuration(12*time.Second, 10, AlignRight)
+	w.WriteTo(os.Stdout)
+
+	// Output:
+	// |   id | name       | state |    elapsed |
+	// | ---: | :--------- | :---: | ---------: |
+	// |    1 | playback   |  off  |  17h31m00s |
+	// |    2 | rec\|ord   |  on   |        12s |
+}
+
+func TestAsMarkdown(t *testing.T) {
+	w := NewWriter(256, AsMarkdown())
+	w.AppendString("a|b", 0, AlignLeft)
+	w.AppendString("multi\nline", 0, AlignLeft)
+	if want, got := `| a\|b | multi line |`, w.String(); want != got {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	var buf bytes.Buffer
+	w = NewWriter(256, AsMarkdown("x", "y", "z"))
+	w.AppendUint(1, 1, AlignRight)
+	w.AppendUint(2, 1, 0)
+	w.WriteTo(&buf)
+	w.AppendUint(3, 1, AlignRight)
+	w.AppendUint(4, 1, 0)
+	w.WriteTo(&buf)
+
+	want := "| x | y | z |\n| --: | --- | --- |\n| 1 | 2 |\n| 3 | 4 |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
 func BenchmarkAppendString(b *testing.B) {
 	w := NewWriter(256, defaults...)
 	for i := 0; i < b.N; i++ {
@@ -80,6 +342,327 @@ func BenchmarkAppendString(b *testing.B) {
 	}
 }
 
+var appends = []struct {
+	Name   string
+	Append func(*Writer)
+}{
+	{Name: "string", Append: func(w *Writer) { w.AppendString("the quick brown fox jumps over the lazy dog", 48, AlignLeft) }},
+	{Name: "truncate", Append: func(w *Writer) {
+		w.AppendString("the quick brown fox jumps over the lazy dog", 12, AlignLeft|TruncateMiddle)
+	}},
+	{Name: "bytes", Append: func(w *Writer) { w.AppendBytes([]byte("the quick brown fox"), 24, AlignLeft) }},
+	{Name: "hex", Append: func(w *Writer) { w.AppendBytes([]byte("the quick brown fox"), 40, AlignLeft|Hex) }},
+	{Name: "time", Append: func(w *Writer) { w.AppendTime(benchTime, time.RFC3339, AlignLeft) }},
+	{Name: "duration", Append: func(w *Writer) { w.AppendDuration(time.Hour+1500*time.Millisecond, 16, AlignRight) }},
+	{Name: "bool", Append: func(w *Writer) { w.AppendBool(true, 5, AlignLeft) }},
+	{Name: "int", Append: func(w *Writer) { w.AppendInt(-1234567, 12, AlignRight|WithGrouping) }},
+	{Name: "uint", Append: func(w *Writer) { w.AppendUint(0xcafe, 8, AlignRight|Hex|WithPrefix|WithZero) }},
+	{Name: "float", Append: func(w *Writer) { w.AppendFloat(1234.5678, 12, 2, AlignRight|Float|WithGrouping) }},
+	{Name: "engineering", Append: func(w *Writer) { w.AppendFloat(0.0047, 8, 2, AlignRight|Engineering|SIPrefix) }},
+	{Name: "percent", Append: func(w *Writer) { w.AppendPercent(0.4567, 8, 1, AlignRight) }},
+	{Name: "size", Append: func(w *Writer) { w.AppendSize(123456789, 8, AlignRight) }},
+	{Name: "unit", Append: func(w *Writer) { w.AppendUnit(2.4e9, "Hz", 8, 1, AlignRight) }},
+	{Name: "rate", Append: func(w *Writer) { w.AppendRate(123456789, time.Second, 12, AlignRight) }},
+}
+
+var benchTime = time.Date(2020, 5, 17, 13, 45, 21, 0, time.UTC)
+
+var allocModes = []struct {
+	Name    string
+	Options []Option
+}{
+	{Name: "text", Options: []Option{WithPadding([]byte(" ")), WithSeparator([]byte("|")), WithLabel("bench")}},
+	{Name: "csv", Options: []Option{AsCSV(true)}},
+	{Name: "dsv", Options: []Option{AsDelimited(';')}},
+	{Name: "json", Options: []Option{AsJSON()}},
+	{Name: "logfmt", Options: []Option{AsLogfmt()}},
+	{Name: "markdown", Options: []Option{AsMarkdown()}},
+	{Name: "locale", Options: []Option{WithLocale(French)}},
+	{Name: "growable", Options: []Option{WithGrowableBuffer()}},
+}
+
+func TestAllocs(t *testing.T) {
+	for _, m := range allocModes {
+		for _, a := range appends {
+			var (
+				w   = NewWriter(1024, m.Options...)
+				buf = make([]byte, 2048)
+			)
+			n := testing.AllocsPerRun(100, func() {
+				a.Append(w)
+				w.Read(buf)
+				a.Append(w)
+				w.WriteTo(io.Discard)
+			})
+			if n != 0 {
+				t.Errorf("%s/%s: want no allocation, got %.1f", m.Name, a.Name, n)
+			}
+		}
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	for _, a := range appends {
+		b.Run(a.Name, func(b *testing.B) {
+			w := NewWriter(256, defaults...)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				a.Append(w)
+				w.Reset()
+			}
+		})
+	}
+}
+
+func benchAppend(b *testing.B, fn func(*Writer)) {
+	w := NewWriter(256, defaults...)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fn(w)
+		w.Reset()
+	}
+}
+
+func benchBaseline(b *testing.B, fn func([]byte) []byte) {
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = fn(buf[:0])
+	}
+}
+
+func benchPrintf(b *testing.B, format string, args ...interface{}) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fmt.Fprintf(io.Discard, format, args...)
+	}
+}
+
+func BenchmarkAppendInt(b *testing.B) {
+	const v = 123456789
+	data := []struct {
+		Name   string
+		Flag   Flag
+		Base   int
+		Format string
+	}{
+		{Name: "decimal", Flag: Decimal, Base: 10, Format: "%12d"},
+		{Name: "decimal-zero", Flag: Decimal | WithZero, Base: 10, Format: "%012d"},
+		{Name: "decimal-sign", Flag: Decimal | WithSign, Base: 10, Format: "%+12d"},
+		{Name: "hex", Flag: Hex, Base: 16, Format: "%12x"},
+		{Name: "hex-zero", Flag: Hex | WithZero, Base: 16, Format: "%012x"},
+		{Name: "hex-prefix", Flag: Hex | WithPrefix, Base: 16, Format: "%#12x"},
+		{Name: "hex-zero-prefix", Flag: Hex | WithZero | WithPrefix, Base: 16, Format: "%#012x"},
+		{Name: "octal", Flag: Octal, Base: 8, Format: "%12o"},
+		{Name: "octal-zero", Flag: Octal | WithZero, Base: 8, Format: "%012o"},
+		{Name: "octal-prefix", Flag: Octal | WithPrefix, Base: 8, Format: "%#12o"},
+		{Name: "binary", Flag: Binary, Base: 2, Format: "%32b"},
+		{Name: "binary-zero", Flag: Binary | WithZero, Base: 2, Format: "%032b"},
+		{Name: "binary-prefix", Flag: Binary | WithPrefix, Base: 2, Format: "%#32b"},
+	}
+	for _, d := range data {
+		width := 12
+		if d.Base == 2 {
+			width = 32
+		}
+		b.Run(d.Name, func(b *testing.B) {
+			benchAppend(b, func(w *Writer) { w.AppendInt(v, width, d.Flag|AlignRight) })
+		})
+		b.Run(d.Name+"/strconv", func(b *testing.B) {
+			benchBaseline(b, func(buf []byte) []byte { return strconv.AppendInt(buf, v, d.Base) })
+		})
+		b.Run(d.Name+"/fmt", func(b *testing.B) {
+			benchPrintf(b, d.Format, v)
+		})
+	}
+	b.Run("grouping", func(b *testing.B) {
+		benchAppend(b, func(w *Writer) { w.AppendInt(v, 12, AlignRight|WithGrouping) })
+	})
+	b.Run("uint", func(b *testing.B) {
+		benchAppend(b, func(w *Writer) { w.AppendUint(v, 12, AlignRight) })
+	})
+	b.Run("uint/strconv", func(b *testing.B) {
+		benchBaseline(b, func(buf []byte) []byte { return strconv.AppendUint(buf, v, 10) })
+	})
+}
+
+func BenchmarkAppendFloat(b *testing.B) {
+	const v = 12345.6789
+	data := []struct {
+		Name   string
+		Flag   Flag
+		Prec   int
+		Format byte
+		Printf string
+	}{
+		{Name: "float", Flag: Float, Prec: 2, Format: 'f', Printf: "%12.2f"},
+		{Name: "float-zero", Flag: Float | WithZero, Prec: 4, Format: 'f', Printf: "%12.4f"},
+		{Name: "float-shortest", Flag: Float, Prec: -1, Format: 'f', Printf: "%12v"},
+		{Name: "scientific", Flag: Scientific, Prec: 3, Format: 'e', Printf: "%12.3e"},
+		{Name: "general", Prec: 6, Format: 'g', Printf: "%12.6g"},
+		{Name: "percent", Flag: Float | Percent, Prec: 1, Format: 'f', Printf: "%11.1f%%"},
+	}
+	for _, d := range data {
+		b.Run(d.Name, func(b *testing.B) {
+			benchAppend(b, func(w *Writer) { w.AppendFloat(v, 12, d.Prec, d.Flag|AlignRight) })
+		})
+		b.Run(d.Name+"/strconv", func(b *testing.B) {
+			benchBaseline(b, func(buf []byte) []byte { return strconv.AppendFloat(buf, v, d.Format, d.Prec, 64) })
+		})
+		b.Run(d.Name+"/fmt", func(b *testing.B) {
+			benchPrintf(b, d.Printf, v)
+		})
+	}
+	b.Run("grouping", func(b *testing.B) {
+		benchAppend(b, func(w *Writer) { w.AppendFloat(v, 12, 2, AlignRight|Float|WithGrouping) })
+	})
+	b.Run("engineering", func(b *testing.B) {
+		benchAppend(b, func(w *Writer) { w.AppendFloat(v, 12, 2, AlignRight|Engineering|SIPrefix) })
+	})
+	b.Run("significant", func(b *testing.B) {
+		benchAppend(b, func(w *Writer) { w.AppendFloat(v, 12, 3, AlignRight|Float|Significant) })
+	})
+}
+
+func BenchmarkAppendDuration(b *testing.B) {
+	const d = 26*time.Hour + 3*time.Minute + 4567891*time.Microsecond
+	for _, f := range []struct {
+		Name string
+		Flag Flag
+	}{
+		{Name: "second", Flag: Second},
+		{Name: "millisecond", Flag: Millisecond},
+		{Name: "microsecond", Flag: Microsecond},
+	} {
+		b.Run(f.Name, func(b *testing.B) {
+			benchAppend(b, func(w *Writer) { w.AppendDuration(d, 24, f.Flag|AlignRight) })
+		})
+	}
+	b.Run("short", func(b *testing.B) {
+		benchAppend(b, func(w *Writer) { w.AppendDuration(1500*time.Microsecond, 24, AlignRight) })
+	})
+	b.Run("string", func(b *testing.B) {
+		benchBaseline(b, func(buf []byte) []byte { return append(buf, d.String()...) })
+	})
+	b.Run("fmt", func(b *testing.B) {
+		benchPrintf(b, "%24v", d)
+	})
+}
+
+func BenchmarkAppendTime(b *testing.B) {
+	for _, layout := range []string{time.RFC3339, time.RFC3339Nano, time.Kitchen, "2006-01-02 15:04:05.000"} {
+		b.Run(layout, func(b *testing.B) {
+			benchAppend(b, func(w *Writer) { w.AppendTime(benchTime, layout, AlignLeft) })
+		})
+		b.Run(layout+"/time", func(b *testing.B) {
+			benchBaseline(b, func(buf []byte) []byte { return benchTime.AppendFormat(buf, layout) })
+		})
+	}
+	b.Run("locale", func(b *testing.B) {
+		w := NewWriter(256, WithLocale(French))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			w.AppendTime(benchTime, "Monday 02 January 2006", AlignLeft)
+			w.Reset()
+		}
+	})
+}
+
+func BenchmarkAppendSize(b *testing.B) {
+	const v = 123456789
+	for _, f := range []struct {
+		Name string
+		Flag Flag
+	}{
+		{Name: "iec", Flag: SizeIEC},
+		{Name: "si", Flag: SizeSI},
+		{Name: "long", Flag: SizeIEC | SizeLong | WithUnitSpace},
+		{Name: "bits", Flag: SizeSI | SizeBits | SizeLong},
+	} {
+		b.Run(f.Name, func(b *testing.B) {
+			benchAppend(b, func(w *Writer) { w.AppendSize(v, 10, f.Flag|AlignRight) })
+		})
+	}
+	b.Run("fmt", func(b *testing.B) {
+		benchPrintf(b, "%9.2fM", float64(v)/(1<<20))
+	})
+}
+
+func BenchmarkLine(b *testing.B) {
+	modes := []struct {
+		Name    string
+		Options []Option
+	}{
+		{Name: "padded", Options: []Option{WithPadding([]byte(" ")), WithSeparator([]byte("|"))}},
+		{Name: "csv", Options: []Option{AsCSV(false)}},
+		{Name: "csv-quoted", Options: []Option{AsCSV(true)}},
+		{Name: "tsv", Options: []Option{AsTSV()}},
+		{Name: "json", Options: []Option{AsJSON("id", "name", "elapsed", "ratio", "size")}},
+		{Name: "logfmt", Options: []Option{AsLogfmt("id", "name", "elapsed", "ratio", "size")}},
+		{Name: "markdown", Options: []Option{AsMarkdown()}},
+	}
+	for _, m := range modes {
+		b.Run(m.Name, func(b *testing.B) {
+			w := NewWriter(256, m.Options...)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w.AppendUint(uint64(i), 8, AlignRight)
+				w.AppendString("playback", 12, AlignLeft)
+				w.AppendDuration(1500*time.Millisecond, 10, AlignRight)
+				w.AppendPercent(0.25, 6, 1, AlignRight)
+				w.AppendSize(123456789, 8, AlignRight)
+				w.WriteTo(io.Discard)
+			}
+		})
+	}
+	b.Run("fmt", func(b *testing.B) {
+		d := 1500 * time.Millisecond
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			fmt.Fprintf(io.Discard, " %8d | %-12s | %10s | %5.1f%% | %7.2fM \n", i, "playback", d, 25.0, 117.74)
+		}
+	})
+	b.Run("encoding/csv", func(b *testing.B) {
+		var (
+			w      = csv.NewWriter(io.Discard)
+			record = make([]string, 5)
+		)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			record[0] = strconv.Itoa(i)
+			record[1] = "playback"
+			record[2] = (1500 * time.Millisecond).String()
+			record[3] = strconv.FormatFloat(25, 'f', 1, 64) + "%"
+			record[4] = strconv.FormatFloat(117.74, 'f', 2, 64) + "M"
+			w.Write(record)
+		}
+		w.Flush()
+	})
+}
+
+func BenchmarkWriteTo(b *testing.B) {
+	w := NewWriter(256, defaults...)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.AppendString("playback", 10, AlignLeft)
+		w.AppendInt(42, 4, AlignRight)
+		w.WriteTo(io.Discard)
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	var (
+		w   = NewWriter(256, defaults...)
+		buf = make([]byte, 512)
+	)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.AppendString("playback", 10, AlignLeft)
+		w.AppendInt(42, 4, AlignRight)
+		w.Read(buf)
+	}
+}
+
 func TestRead(t *testing.T) {
 	w1 := NewWriter(256, WithPadding([]byte("_")), WithSeparator([]byte("|")))
 	w1.AppendUint(1, 4, AlignRight)
@@ -207,6 +790,10 @@ func TestAppendInt(t *testing.T) {
 		{Value: 3, Flags: AlignLeft | Decimal, Want: "_3    _"},
 		{Value: 3, Flags: AlignLeft | WithSign | Decimal, Want: "_+3   _"},
 		{Value: 15, Flags: AlignRight | WithPrefix | Hex, Want: "_  0xf_"},
+		{Value: -42, Flags: AlignRight | WithZero | Decimal, Want: "_-0042_"},
+		{Value: -42, Flags: AlignRight | WithZero | WithPrefix | Hex, Want: "_-0x2a_"},
+		{Value: 0, Flags: AlignRight | WithSign | Decimal, Want: "_   +0_"},
+		{Value: 0, Flags: AlignRight | WithSign | WithZero | Decimal, Want: "_+0000_"},
 	}
 	for i, d := range data {
 		w.AppendInt(d.Value, 5, d.Flags)
@@ -219,6 +806,65 @@ func TestAppendInt(t *testing.T) {
 	}
 }
 
+func ExampleWriter_AppendIntBits() {
+	w := NewWriter(64, WithSeparator([]byte(" ")))
+	w.AppendIntBits(-1, 16, 6, AlignRight|Hex|WithPrefix)
+	w.AppendIntBits(-128, 8, 10, AlignRight|Binary)
+	w.AppendIntBits(0xfffe, 16, 6, AlignRight|Decimal)
+
+	fmt.Println(w.String())
+	// Output:
+	// 0xffff   10000000     -2
+}
+
+func TestAppendIntBits(t *testing.T) {
+	data := []struct {
+		Value int64
+		Bits  int
+		Width int
+		Flags Flag
+		Want  string
+		Err   error
+	}{
+		{Value: -1, Bits: 16, Flags: Hex, Want: "ffff"},
+		{Value: -1, Bits: 8, Flags: Hex | WithPrefix, Want: "0xff"},
+		{Value: 1, Bits: 16, Flags: Hex, Want: "0001"},
+		{Value: 1, Bits: 12, Flags: Hex, Want: "001"},
+		{Value: -2, Bits: 32, Flags: Hex, Want: "fffffffe"},
+		{Value: -1, Bits: 64, Flags: Hex, Want: "ffffffffffffffff"},
+		{Value: math.MinInt64, Bits: 64, Flags: Hex | WithPrefix, Want: "0x8000000000000000"},
+		{Value: -128, Bits: 8, Flags: Binary, Want: "10000000"},
+		{Value: 5, Bits: 8, Flags: Binary | WithPrefix, Want: "0b00000101"},
+		{Value: -1, Bits: 5, Flags: Binary, Want: "11111"},
+		{Value: -1, Bits: 8, Flags: Octal, Want: "377"},
+		{Value: 1, Bits: 16, Flags: Octal | WithPrefix, Want: "0o000001"},
+		{Value: -1, Bits: 64, Flags: Octal, Want: "1777777777777777777777"},
+		{Value: 0x1ff, Bits: 8, Flags: Hex, Want: "ff"},
+		{Value: -1, Bits: 8, Flags: Hex | WithSign, Want: "ff"},
+		{Value: -1, Bits: 16, Width: 8, Flags: Hex | AlignRight, Want: "    ffff"},
+		{Value: -1, Bits: 16, Width: 8, Flags: Hex | AlignRight | WithZero, Want: "0000ffff"},
+		{Value: -1, Bits: 16, Flags: Hex | WithGrouping, Want: "ffff"},
+		{Value: -1, Bits: 24, Flags: Hex | WithGrouping, Want: "ff_ffff"},
+		{Value: -1, Bits: 32, Flags: Binary | WithGrouping, Want: "1111_1111_1111_1111_1111_1111_1111_1111"},
+		{Value: 255, Bits: 8, Flags: Decimal, Want: "-1"},
+		{Value: 127, Bits: 8, Flags: Decimal | WithSign, Want: "+127"},
+		{Value: -1, Bits: 16, Width: 6, Flags: Decimal | WithZero | AlignRight, Want: "-00001"},
+		{Value: 42, Bits: 0, Flags: Hex, Want: "2a", Err: ErrBits},
+		{Value: 42, Bits: 65, Flags: Decimal, Want: "42", Err: ErrBits},
+		{Value: -1, Bits: 8, Flags: Hex | Binary, Want: "ff", Err: ErrFlag},
+	}
+	for i, d := range data {
+		w := NewWriter(256, WithFlag(NoPadding))
+		w.AppendIntBits(d.Value, d.Bits, d.Width, d.Flags)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+		if err := w.Err(); err != d.Err {
+			t.Errorf("%d: want error %v, got %v", i+1, d.Err, err)
+		}
+	}
+}
+
 func TestAppendUint(t *testing.T) {
 	w := NewWriter(256, defaults...)
 	data := []struct {
@@ -239,7 +885,7 @@ func TestAppendUint(t *testing.T) {
 		{Value: 453721, Flags: Decimal | WithSign | AlignLeft, Want: "_+453721   _"},
 		{Value: 453721, Flags: Hex | WithPrefix | AlignLeft, Want: "_0x6ec59   _"},
 		{Value: 453721, Flags: Hex | WithPrefix | AlignRight, Want: "_   0x6ec59_"},
-		{Value: 453721, Flags: Hex | WithZero | WithPrefix | AlignLeft, Want: "_0x0006ec59_"},
+		{Value: 453721, Flags: Hex | WithZero | WithPrefix | AlignLeft, Want: "_0x6ec59   _"},
 		{Value: 5, Flags: Binary | AlignRight, Want: "_       101_"},
 		{Value: 5, Flags: Binary | WithPrefix | AlignRight, Want: "_     0b101_"},
 		{Value: 5, Flags: Octal | AlignRight, Want: "_         5_"},
@@ -256,6 +902,79 @@ func TestAppendUint(t *testing.T) {
 	}
 }
 
+func TestPrintfIntegers(t *testing.T) {
+	var (
+		values = []int64{0, 1, -1, 42, -42, 255, -255, math.MaxInt64, math.MinInt64}
+		widths = []int{0, 1, 2, 3, 4, 8, 24, 70}
+		bases  = []struct {
+			Flag Flag
+			Verb string
+		}{
+			{Flag: Decimal, Verb: "d"},
+			{Flag: Hex, Verb: "x"},
+			{Flag: Octal, Verb: "o"},
+			{Flag: Binary, Verb: "b"},
+		}
+		options = []struct {
+			Flag   Flag
+			Printf string
+		}{
+			{Flag: WithZero, Printf: "0"},
+			{Flag: WithSign, Printf: "+"},
+			{Flag: WithPrefix, Printf: "#"},
+			{Flag: AlignLeft, Printf: "-"},
+		}
+	)
+	for _, b := range bases {
+		for set := 0; set < 1<<len(options); set++ {
+			var (
+				flag   = b.Flag | NoPadding | AlignRight
+				format = "%"
+				verb   = b.Verb
+			)
+			for i, o := range options {
+				if set&(1<<i) == 0 {
+					continue
+				}
+				flag |= o.Flag
+				if o.Flag == WithPrefix && b.Flag == Octal {
+					verb = "O"
+					continue
+				}
+				format += o.Printf
+			}
+			if flag&AlignLeft != 0 {
+				flag &^= AlignRight
+			}
+			for _, width := range widths {
+				// fmt does not count the base prefix when zero padding,
+				// linewriter keeps it inside the requested width.
+				padding := width
+				if flag&(WithZero|WithPrefix|AlignLeft) == WithZero|WithPrefix && b.Flag != Decimal && padding >= 2 {
+					padding -= 2
+				}
+				for _, v := range values {
+					w := NewWriter(128, WithFlag(NoPadding))
+					w.AppendInt(v, width, flag)
+
+					want := fmt.Sprintf(format+"*"+verb, padding, v)
+					if got := w.String(); got != want {
+						t.Errorf("%q(%d, %d): want %q, got %q", format+verb, v, width, want, got)
+					}
+					if v < 0 {
+						continue
+					}
+					w.Reset()
+					w.AppendUint(uint64(v), width, flag)
+					if got := w.String(); got != want {
+						t.Errorf("%q(%d, %d) unsigned: want %q, got %q", format+verb, v, width, want, got)
+					}
+				}
+			}
+		}
+	}
+}
+
 func TestAppendBool(t *testing.T) {
 	w := NewWriter(256, defaults...)
 	data := []struct {
@@ -309,3 +1028,618 @@ func TestAppendTime(t *testing.T) {
 		}
 	}
 }
+
+var boundaries = []struct {
+	Name   string
+	Append func(*Writer)
+}{
+	{Name: "string", Append: func(w *Writer) { w.AppendString("playback", 10, AlignLeft) }},
+	{Name: "bytes", Append: func(w *Writer) { w.AppendBytes([]byte("playback"), 10, AlignLeft|Hex) }},
+	{Name: "int", Append: func(w *Writer) { w.AppendInt(-453721, 10, AlignRight) }},
+	{Name: "uint", Append: func(w *Writer) { w.AppendUint(453721, 10, AlignRight|Hex|WithZero|WithPrefix) }},
+	{Name: "float", Append: func(w *Writer) { w.AppendFloat(3.14159, 10, 4, AlignRight|Float) }},
+	{Name: "percent", Append: func(w *Writer) { w.AppendPercent(0.9845, 10, 2, AlignRight) }},
+	{Name: "bool", Append: func(w *Writer) { w.AppendBool(false, 10, AlignCenter|TrueFalse) }},
+	{Name: "duration", Append: func(w *Writer) { w.AppendDuration(17*time.Hour+31*time.Minute, 10, AlignRight) }},
+	{Name: "time", Append: func(w *Writer) {
+		w.AppendTime(time.Date(2019, 6, 11, 12, 25, 43, 0, time.UTC), time.RFC3339, AlignLeft)
+	}},
+	{Name: "size", Append: func(w *Writer) { w.AppendSize(1<<20, 10, AlignRight) }},
+	{Name: "separator", Append: func(w *Writer) { w.AppendSeparator(12) }},
+}
+
+func TestGrowableBuffer(t *testing.T) {
+	for _, b := range boundaries {
+		want := NewWriter(256, defaults...)
+		b.Append(want)
+		b.Append(want)
+
+		for _, size := range []int{0, 1, 8, 16} {
+			w := NewWriter(size, append(defaults, WithGrowableBuffer())...)
+			b.Append(w)
+			b.Append(w)
+			if got := w.String(); got != want.String() {
+				t.Errorf("%s(%d): want %q, got %q", b.Name, size, want.String(), got)
+				continue
+			}
+			var buf bytes.Buffer
+			if _, err := w.WriteTo(&buf); err != io.EOF {
+				t.Errorf("%s(%d): unexpected error: %s", b.Name, size, err)
+			}
+			if got := buf.String(); got != want.String()+"\n" {
+				t.Errorf("%s(%d): want %q, got %q", b.Name, size, want.String()+"\n", got)
+			}
+		}
+	}
+}
+
+func TestStrictBuffer(t *testing.T) {
+	for _, b := range boundaries {
+		ref := NewWriter(256, defaults...)
+		b.Append(ref)
+		size := len(ref.Bytes())
+
+		w := NewWriter(size, append(defaults, WithStrictBuffer())...)
+		b.Append(w)
+		if _, err := w.WriteTo(io.Discard); err != io.EOF {
+			t.Errorf("%s: unexpected error at boundary: %s", b.Name, err)
+		}
+		w = NewWriter(size-1, append(defaults, WithStrictBuffer())...)
+		b.Append(w)
+		if _, err := w.WriteTo(io.Discard); err != ErrOverflow {
+			t.Errorf("%s: want %s, got %v", b.Name, ErrOverflow, err)
+		}
+		if _, err := w.Read(make([]byte, 256)); err != io.EOF {
+			t.Errorf("%s: error not cleared after reset: %v", b.Name, err)
+		}
+	}
+}
+
+func TestTruncateBuffer(t *testing.T) {
+	for _, b := range boundaries {
+		for _, size := range []int{0, 1, 8} {
+			w := NewWriter(size, defaults...)
+			b.Append(w)
+			b.Append(w)
+			if got := len(w.Bytes()); got > size {
+				t.Errorf("%s(%d): line longer than buffer (%d)", b.Name, size, got)
+			}
+			if err := w.Err(); err != ErrOverflow {
+				t.Errorf("%s(%d): want %s, got %v", b.Name, size, ErrOverflow, err)
+			}
+			var buf bytes.Buffer
+			if _, err := w.WriteTo(&buf); size > 0 && (err != ErrOverflow || buf.Len() != size+1) {
+				t.Errorf("%s(%d): truncated line not reported (%q, %v)", b.Name, size, buf.String(), err)
+			}
+			if err := w.Err(); err != nil {
+				t.Errorf("%s(%d): error not cleared after WriteTo: %v", b.Name, size, err)
+			}
+
+			b.Append(w)
+			b.Append(w)
+			if n, err := w.Read(make([]byte, 2*size+2)); size > 0 && (err != ErrOverflow || n != size+1) {
+				t.Errorf("%s(%d): truncated line not reported by Read (%d, %v)", b.Name, size, n, err)
+			}
+		}
+	}
+}
+
+func TestZeroPaddingBuffer(t *testing.T) {
+	data := []struct {
+		Name   string
+		Append func(*Writer)
+	}{
+		{Name: "int", Append: func(w *Writer) { w.AppendInt(-1, 1<<40, AlignRight|WithZero) }},
+		{Name: "uint", Append: func(w *Writer) { w.AppendUint(1, 1<<40, AlignCenter|WithZero|WithGrouping) }},
+		{Name: "bits", Append: func(w *Writer) { w.AppendIntBits(-1, 16, 1<<40, AlignRight|WithZero|Hex) }},
+	}
+	for _, d := range data {
+		w := NewWriter(64)
+		d.Append(w)
+		if got := len(w.Bytes()); got != 64 {
+			t.Errorf("%s: want line of 64 bytes, got %d", d.Name, got)
+		}
+		if got := cap(w.tmp); got > 512 {
+			t.Errorf("%s: temporary buffer grew to %d bytes", d.Name, got)
+		}
+	}
+}
+
+func TestErr(t *testing.T) {
+	data := []struct {
+		Name   string
+		Append func(*Writer)
+		Want   error
+	}{
+		{Name: "valid", Want: nil, Append: func(w *Writer) {
+			w.AppendUint(1, 4, AlignRight|Hex)
+			w.AppendTime(time.Now(), time.RFC3339, AlignLeft)
+		}},
+		{Name: "align", Want: ErrFlag, Append: func(w *Writer) { w.AppendString("playback", 10, AlignLeft|AlignCenter) }},
+		{Name: "base", Want: ErrFlag, Append: func(w *Writer) { w.AppendInt(-1, 4, AlignRight|Hex|Octal) }},
+		{Name: "bool", Want: ErrFlag, Append: func(w *Writer) { w.AppendBool(true, 4, YesNo|OnOff) }},
+		{Name: "duration", Want: ErrFlag, Append: func(w *Writer) { w.AppendDuration(time.Second, 4, Millisecond|Microsecond) }},
+		{Name: "time", Want: ErrTimeFormat, Append: func(w *Writer) { w.AppendTime(time.Now(), "today", AlignLeft) }},
+		{Name: "overflow", Want: ErrOverflow, Append: func(w *Writer) { w.AppendString("playback", 512, AlignLeft) }},
+		{Name: "sticky", Want: ErrTimeFormat, Append: func(w *Writer) {
+			w.AppendTime(time.Now(), "", AlignLeft)
+			w.AppendUint(1, 4, AlignRight|Hex|Binary)
+			w.AppendUint(1, 4, AlignRight|Hex)
+		}},
+	}
+	for _, d := range data {
+		w := NewWriter(256, append(defaults, WithStrictBuffer())...)
+		d.Append(w)
+		if err := w.Err(); err != d.Want {
+			t.Errorf("%s: want %v, got %v", d.Name, d.Want, err)
+			continue
+		}
+		want := d.Want
+		if want == nil {
+			want = io.EOF
+		}
+		if _, err := w.WriteTo(io.Discard); err != want {
+			t.Errorf("%s: WriteTo: want %v, got %v", d.Name, want, err)
+		}
+		if err := w.Err(); err != nil {
+			t.Errorf("%s: error not cleared after WriteTo: %v", d.Name, err)
+		}
+
+		d.Append(w)
+		if _, err := w.Read(make([]byte, 512)); d.Want != nil && err != d.Want {
+			t.Errorf("%s: Read: want %v, got %v", d.Name, d.Want, err)
+		}
+	}
+}
+
+func TestDefaultFlagsOverride(t *testing.T) {
+	data := []struct {
+		Append func(*Writer)
+		Want   string
+	}{
+		{Want: "_  2a_", Append: func(w *Writer) { w.AppendInt(42, 4, DefaultFlags|Hex) }},
+		{Want: "_0x2a_", Append: func(w *Writer) { w.AppendUint(42, 4, DefaultFlags|Hex|WithPrefix) }},
+		{Want: "_f6_", Append: func(w *Writer) { w.AppendIntBits(-10, 8, 0, DefaultFlags|Hex) }},
+		{Want: "_1.5s_", Append: func(w *Writer) { w.AppendDuration(1500*time.Millisecond, 0, DefaultFlags|Millisecond) }},
+		{Want: "_ yes_", Append: func(w *Writer) { w.AppendBool(true, 4, DefaultFlags|YesNo) }},
+		{Want: "_ok   _", Append: func(w *Writer) { w.AppendString("ok", 5, DefaultFlags|AlignLeft) }},
+	}
+	for i, d := range data {
+		w := NewWriter(256, defaults...)
+		d.Append(w)
+		if err := w.Err(); err != nil {
+			t.Errorf("%d: unexpected error: %v", i+1, err)
+			continue
+		}
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+
+	w := NewWriter(256, defaults...)
+	w.AppendInt(42, 4, DefaultFlags|Hex|Octal)
+	if err := w.Err(); err != ErrFlag {
+		t.Errorf("want %v, got %v", ErrFlag, err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	data := []struct {
+		Value    string
+		Width    int
+		Flags    Flag
+		Ellipsis string
+		Want     string
+	}{
+		{Value: "playback", Width: 10, Flags: AlignLeft | Truncate, Want: "playback  "},
+		{Value: "playback", Width: 8, Flags: AlignLeft | Truncate, Want: "playback"},
+		{Value: "playback", Width: 6, Flags: AlignLeft | Truncate, Want: "playb…"},
+		{Value: "playback", Width: 6, Flags: AlignLeft | TruncateLeft, Want: "…yback"},
+		{Value: "playback", Width: 6, Flags: AlignLeft | TruncateMiddle, Want: "pla…ck"},
+		{Value: "playback", Width: 7, Flags: AlignLeft | TruncateMiddle, Want: "pla…ack"},
+		{Value: "playback", Width: 6, Flags: AlignLeft | Truncate, Ellipsis: "...", Want: "pla..."},
+		{Value: "playback", Width: 6, Flags: AlignLeft | TruncateLeft, Ellipsis: "...", Want: "...ack"},
+		{Value: "playback", Width: 3, Flags: AlignLeft | Truncate, Ellipsis: "...", Want: "pla"},
+		{Value: "playback", Width: 1, Flags: AlignLeft | Truncate, Want: "p"},
+		{Value: "日本語です", Width: 6, Flags: AlignLeft | Truncate, Want: "日本… "},
+		{Value: "日本語です", Width: 6, Flags: AlignRight | TruncateLeft, Want: " …です"},
+		{Value: "日本語です", Width: 7, Flags: AlignLeft | TruncateMiddle, Want: "日…です"},
+		{Value: "👩‍💻👩‍💻👩‍💻", Width: 5, Flags: AlignLeft | Truncate, Want: "👩‍💻👩‍💻…"},
+	}
+	for i, d := range data {
+		options := []Option{WithFlag(DefaultFlags | NoPadding)}
+		if d.Ellipsis != "" {
+			options = append(options, WithEllipsis(d.Ellipsis))
+		}
+		w := NewWriter(256, options...)
+		w.AppendString(d.Value, d.Width, d.Flags)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+		if got := displayWidth(w.Bytes()); got != d.Width {
+			t.Errorf("%d: want width %d, got %d", i+1, d.Width, got)
+		}
+	}
+
+	w := NewWriter(256)
+	w.AppendString("playback", 6, Truncate|TruncateLeft)
+	if err := w.Err(); err != ErrFlag {
+		t.Errorf("want %v, got %v", ErrFlag, err)
+	}
+}
+
+func TestOverflowMarker(t *testing.T) {
+	data := []struct {
+		Options []Option
+		Append  func(*Writer)
+		Want    string
+	}{
+		{
+			Options: []Option{WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendInt(-123456, 6, AlignRight) },
+			Want:    "######",
+		},
+		{
+			Options: []Option{WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendInt(-12345, 6, AlignRight) },
+			Want:    "-12345",
+		},
+		{
+			Options: []Option{WithOverflowMarker('*')},
+			Append:  func(w *Writer) { w.AppendUint(0xdeadbeef, 4, AlignRight|Hex) },
+			Want:    "****",
+		},
+		{
+			Options: []Option{WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendFloat(3.14159, 4, 3, AlignRight|Float) },
+			Want:    "####",
+		},
+		{
+			Options: []Option{WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendPercent(0.98456, 5, 2, AlignRight) },
+			Want:    "#####",
+		},
+		{
+			Options: []Option{WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendSize(1536, 3, AlignRight) },
+			Want:    "###",
+		},
+		{
+			Options: []Option{WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendString("playback", 3, AlignRight) },
+			Want:    "playback",
+		},
+		{
+			Options: []Option{WithOverflowMarker('#'), AsCSV(false)},
+			Append:  func(w *Writer) { w.AppendInt(-123456, 3, AlignRight) },
+			Want:    "-123456",
+		},
+		{
+			Options: []Option{WithAutoScientific()},
+			Append:  func(w *Writer) { w.AppendFloat(123456789, 8, 2, AlignRight|Float) },
+			Want:    "1.23e+08",
+		},
+		{
+			Options: []Option{WithAutoScientific()},
+			Append:  func(w *Writer) { w.AppendFloat(123456789, 5, -1, AlignRight) },
+			Want:    "1e+08",
+		},
+		{
+			Options: []Option{WithAutoScientific(), WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendFloat(123456789, 4, 2, AlignRight|Float) },
+			Want:    "####",
+		},
+		{
+			Options: []Option{WithAutoScientific(), WithOverflowMarker('#')},
+			Append:  func(w *Writer) { w.AppendFloat(12345.6, 8, 2, AlignRight|Float) },
+			Want:    " 12345.6",
+		},
+		{
+			Options: []Option{WithAutoScientific()},
+			Append:  func(w *Writer) { w.AppendFloat(1e10, 12, 2, AlignRight|Scientific) },
+			Want:    "       1e+10",
+		},
+	}
+	for i, d := range data {
+		w := NewWriter(256, append([]Option{WithFlag(DefaultFlags | NoPadding)}, d.Options...)...)
+		d.Append(w)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func TestGrouping(t *testing.T) {
+	data := []struct {
+		Options []Option
+		Append  func(*Writer)
+		Want    string
+	}{
+		{Append: func(w *Writer) { w.AppendInt(1234567, 12, AlignRight|WithGrouping) }, Want: "   1,234,567"},
+		{Append: func(w *Writer) { w.AppendInt(1234567, 12, AlignRight) }, Want: "     1234567"},
+		{Append: func(w *Writer) { w.AppendInt(-1234567, 12, AlignRight|WithGrouping) }, Want: "  -1,234,567"},
+		{Append: func(w *Writer) { w.AppendInt(123, 12, AlignRight|WithGrouping) }, Want: "         123"},
+		{Append: func(w *Writer) { w.AppendInt(1234, 12, AlignLeft|WithGrouping|WithSign) }, Want: "+1,234      "},
+		{Append: func(w *Writer) { w.AppendInt(1234, 10, AlignRight|WithGrouping|WithZero) }, Want: "000001,234"},
+		{Append: func(w *Writer) { w.AppendInt(-1234, 10, AlignRight|WithGrouping|WithZero) }, Want: "-00001,234"},
+		{Append: func(w *Writer) { w.AppendInt(1234, 10, AlignRight|WithGrouping|WithZero|WithSign) }, Want: "+00001,234"},
+		{Append: func(w *Writer) { w.AppendUint(18446744073709551615, 26, AlignRight|WithGrouping) }, Want: "18,446,744,073,709,551,615"},
+		{Append: func(w *Writer) { w.AppendUint(0xdeadbeef, 10, AlignRight|WithGrouping|Hex) }, Want: " dead_beef"},
+		{Append: func(w *Writer) { w.AppendUint(0o12345670, 10, AlignRight|WithGrouping|Octal) }, Want: " 1234_5670"},
+		{Append: func(w *Writer) { w.AppendUint(0xbeef, 11, AlignRight|WithGrouping|WithZero|Hex) }, Want: "0000000beef"},
+		{
+			Options: []Option{WithGroupSeparator(" ")},
+			Append:  func(w *Writer) { w.AppendUint(0x1beef, 10, AlignRight|WithGrouping|WithPrefix|Hex) },
+			Want:    "  0x1_beef",
+		},
+		{
+			Options: []Option{WithGroupSeparator(" ")},
+			Append:  func(w *Writer) { w.AppendInt(1234567, 12, AlignRight|WithGrouping) },
+			Want:    "   1 234 567",
+		},
+		{
+			Options: []Option{WithGroupSeparator(",", 3, 2)},
+			Append:  func(w *Writer) { w.AppendInt(1234567, 12, AlignRight|WithGrouping) },
+			Want:    "   12,34,567",
+		},
+		{
+			Options: []Option{WithGroupSeparator(",", 3, 2)},
+			Append:  func(w *Writer) { w.AppendInt(-123456789, 12, AlignRight|WithGrouping) },
+			Want:    "-12,34,56,789",
+		},
+		{
+			Options: []Option{WithGroupSeparator("_", 4)},
+			Append:  func(w *Writer) { w.AppendUint(0xdeadbeef, 10, AlignRight|WithGrouping|WithPrefix|Hex) },
+			Want:    "0xdead_beef",
+		},
+		{
+			Options: []Option{WithGroupSeparator(" ")},
+			Append:  func(w *Writer) { w.AppendInt(1234567, 10, AlignRight|WithGrouping|WithZero) },
+			Want:    "01 234 567",
+		},
+		{Append: func(w *Writer) { w.AppendFloat(1234567.891, 14, 2, AlignRight|WithGrouping|Float) }, Want: "  1,234,567.89"},
+		{Append: func(w *Writer) { w.AppendFloat(-1234.5, 14, 2, AlignRight|WithGrouping|Float|WithZero) }, Want: "     -1,234.50"},
+		{Append: func(w *Writer) { w.AppendFloat(999.5, 14, 2, AlignRight|WithGrouping|Float) }, Want: "         999.5"},
+		{Append: func(w *Writer) { w.AppendFloat(1234.5, 12, 1, AlignRight|WithGrouping|WithSign|Float) }, Want: "    +1,234.5"},
+		{Append: func(w *Writer) { w.AppendFloat(-1234.5, 12, 1, AlignRight|WithGrouping|WithSign|Float) }, Want: "    -1,234.5"},
+		{Append: func(w *Writer) { w.AppendFloat(0, 4, 1, AlignRight|WithSign|Float) }, Want: "  +0"},
+		{Append: func(w *Writer) { w.AppendFloat(math.Inf(1), 4, 1, AlignRight|WithSign|Float) }, Want: "+Inf"},
+		{Append: func(w *Writer) { w.AppendPercent(0.5, 4, 0, AlignRight|WithSign) }, Want: "+50%"},
+		{Append: func(w *Writer) { w.AppendPercent(12.3456, 14, 2, AlignRight|WithGrouping) }, Want: "     1,234.56%"},
+		{
+			Options: []Option{WithGroupSeparator(",", 0)},
+			Append:  func(w *Writer) { w.AppendFloat(1234567.5, 11, 1, AlignRight|WithGrouping|Float) },
+			Want:    "1,234,567.5",
+		},
+		{
+			Options: []Option{WithGroupSeparator(",", -1, 0)},
+			Append:  func(w *Writer) { w.AppendPercent(12345, 10, 0, AlignRight|WithGrouping) },
+			Want:    "1,234,500%",
+		},
+		{
+			Options: []Option{WithLocale(Locale{Group: ".", Grouping: []int{-1}})},
+			Append:  func(w *Writer) { w.AppendFloat(1234567.5, 11, 1, AlignRight|WithGrouping|Float) },
+			Want:    "1.234.567.5",
+		},
+	}
+	for i, d := range data {
+		w := NewWriter(256, append([]Option{WithFlag(DefaultFlags | NoPadding)}, d.Options...)...)
+		d.Append(w)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func fuzzAppend(t *testing.T, width int, flag Flag, fn func(*Writer, int, Flag)) []byte {
+	t.Helper()
+	for _, options := range [][]Option{defaults, {WithStrictBuffer()}, {AsCSV(false)}, {AsJSON()}, {AsMarkdown()}} {
+		w := NewWriter(64, options...)
+		fn(w, width, flag)
+		w.WriteTo(io.Discard)
+	}
+	if width < 0 || width > 1024 {
+		return nil
+	}
+	w := NewWriter(0, WithGrowableBuffer())
+	fn(w, width, flag)
+	out := w.Bytes()
+	if n := displayWidth(out); isWithSpace(w.flags, flag) && n < width {
+		t.Fatalf("%q: want width >= %d, got %d", out, width, n)
+	}
+	return out
+}
+
+func fuzzBase(flag Flag) int {
+	switch {
+	case flag&Hex != 0:
+		return 16
+	case flag&Octal != 0:
+		return 8
+	case flag&Binary != 0:
+		return 2
+	default:
+		return 10
+	}
+}
+
+func fuzzInteger(t *testing.T, out []byte, flag Flag) string {
+	t.Helper()
+	str := strings.TrimSpace(string(out))
+	if fuzzBase(flag) != 10 && flag&WithPrefix != 0 {
+		str = strings.Replace(str, "0o", "0", 1)
+	}
+	return str
+}
+
+func FuzzAppendString(f *testing.F) {
+	f.Add("playback", 10, uint64(AlignLeft))
+	f.Add("日本語", 5, uint64(AlignCenter|TruncateMiddle))
+	f.Add("the quick brown fox", 4, uint64(AlignRight|Truncate))
+	f.Add("", 1<<20, uint64(AlignLeft))
+	f.Add("a|b", -1, uint64(NoSpace|WithQuote))
+	f.Fuzz(func(t *testing.T, str string, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendString(str, width, flag)
+		})
+	})
+}
+
+func FuzzAppendBytes(f *testing.F) {
+	f.Add([]byte("playback"), 10, uint64(AlignLeft))
+	f.Add([]byte{0, 0xff, 0x7f}, 6, uint64(AlignRight|Hex))
+	f.Add([]byte{0xe6, 0x97}, 3, uint64(AlignLeft|TruncateLeft))
+	f.Fuzz(func(t *testing.T, bs []byte, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendBytes(bs, width, flag)
+		})
+	})
+}
+
+func FuzzAppendInt(f *testing.F) {
+	f.Add(int64(-42), 6, uint64(AlignRight|WithZero))
+	f.Add(int64(-42), 6, uint64(AlignRight|WithZero|WithSign|Hex|WithPrefix))
+	f.Add(int64(math.MinInt64), 70, uint64(AlignLeft|Binary|WithZero))
+	f.Add(int64(1234567), 1<<30, uint64(AlignCenter|WithGrouping))
+	f.Add(int64(0), 0, uint64(WithSign|Octal|WithPrefix))
+	f.Fuzz(func(t *testing.T, v int64, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendInt(v, width, flag)
+		})
+
+		number := Flag(flag)&(alignFlags|baseFlags|WithZero|WithSign|WithPrefix) | NoPadding
+		if width = width % 128; width < 0 {
+			width = -width
+		}
+		w := NewWriter(0, WithGrowableBuffer())
+		w.AppendInt(v, width, number)
+		str := fuzzInteger(t, w.Bytes(), number)
+		base := fuzzBase(number)
+		if number&WithPrefix != 0 && base != 10 {
+			base = 0
+		}
+		got, err := strconv.ParseInt(str, base, 64)
+		if err != nil || got != v {
+			t.Fatalf("%q: want %d, got %d (%v)", str, v, got, err)
+		}
+	})
+}
+
+func FuzzAppendUint(f *testing.F) {
+	f.Add(uint64(42), 6, uint64(AlignRight|WithZero))
+	f.Add(uint64(math.MaxUint64), 70, uint64(AlignLeft|Binary|WithZero|WithPrefix))
+	f.Add(uint64(0xcafe), 10, uint64(AlignRight|Hex|WithZero|WithSign))
+	f.Fuzz(func(t *testing.T, v uint64, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendUint(v, width, flag)
+		})
+
+		number := Flag(flag)&(alignFlags|baseFlags|WithZero|WithSign|WithPrefix) | NoPadding
+		if width = width % 128; width < 0 {
+			width = -width
+		}
+		w := NewWriter(0, WithGrowableBuffer())
+		w.AppendUint(v, width, number)
+		str := strings.TrimPrefix(fuzzInteger(t, w.Bytes(), number), "+")
+		base := fuzzBase(number)
+		if number&WithPrefix != 0 && base != 10 {
+			base = 0
+		}
+		got, err := strconv.ParseUint(str, base, 64)
+		if err != nil || got != v {
+			t.Fatalf("%q: want %d, got %d (%v)", str, v, got, err)
+		}
+	})
+}
+
+func FuzzAppendFloat(f *testing.F) {
+	f.Add(3.14159, 10, 2, uint64(AlignRight|Float))
+	f.Add(-0.000123, 12, 3, uint64(AlignLeft|Scientific|WithZero))
+	f.Add(1e300, 5, -1, uint64(AlignRight|Float|WithGrouping))
+	f.Add(math.NaN(), 4, 2, uint64(AlignRight|Percent))
+	f.Add(4700.0, 8, 2, uint64(AlignRight|Engineering|SIPrefix|Significant))
+	f.Fuzz(func(t *testing.T, v float64, width, prec int, flag uint64) {
+		if prec > 64 || prec < -1 {
+			prec = prec % 64
+		}
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendFloat(v, width, prec, flag)
+		})
+
+		number := Flag(flag)&(alignFlags|WithZero|Float|Scientific) | NoPadding
+		w := NewWriter(0, WithGrowableBuffer())
+		w.AppendFloat(v, 0, prec, number)
+		str := strings.TrimSpace(w.String())
+
+		format := byte('g')
+		if number&Scientific != 0 {
+			format = 'e'
+		} else if number&Float != 0 {
+			format = 'f'
+		}
+		want, _ := strconv.ParseFloat(strconv.FormatFloat(v, format, prec, 64), 64)
+		got, err := strconv.ParseFloat(str, 64)
+		if err != nil || (got != want && !(math.IsNaN(got) && math.IsNaN(want))) {
+			t.Fatalf("%q: want %v, got %v (%v)", str, want, got, err)
+		}
+	})
+}
+
+func FuzzAppendBool(f *testing.F) {
+	f.Add(true, 5, uint64(AlignLeft|YesNo))
+	f.Add(false, 1, uint64(AlignCenter|OnOff|OneZero))
+	f.Fuzz(func(t *testing.T, b bool, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendBool(b, width, flag)
+		})
+	})
+}
+
+func FuzzAppendDuration(f *testing.F) {
+	f.Add(int64(time.Hour+time.Second), 12, uint64(AlignRight|Millisecond))
+	f.Add(int64(math.MinInt64), 4, uint64(AlignLeft|Microsecond))
+	f.Add(int64(-1), 0, uint64(Second|Millisecond))
+	f.Fuzz(func(t *testing.T, d int64, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendDuration(time.Duration(d), width, flag)
+		})
+	})
+}
+
+func FuzzAppendTime(f *testing.F) {
+	f.Add(int64(0), time.RFC3339, uint64(AlignLeft))
+	f.Add(int64(1589723121), "Monday 02 January 2006", uint64(AlignRight|Truncate))
+	f.Add(int64(-1), "", uint64(0))
+	f.Fuzz(func(t *testing.T, sec int64, layout string, flag uint64) {
+		when := time.Unix(sec%(1<<40), 0).UTC()
+		fuzzAppend(t, 0, Flag(flag), func(w *Writer, _ int, flag Flag) {
+			w.AppendTime(when, layout, flag)
+		})
+		w := NewWriter(0, WithGrowableBuffer(), WithLocale(German))
+		w.AppendTime(when, layout, Flag(flag))
+	})
+}
+
+func FuzzAsCSV(f *testing.F) {
+	f.Add("playback", "hello, world", false)
+	f.Add("\"quoted\"", "multi\nline", true)
+	f.Add("", "", false)
+	f.Fuzz(func(t *testing.T, first, second string, quoted bool) {
+		if strings.ContainsRune(first+second, '\r') || !utf8.ValidString(first+second) {
+			return
+		}
+		w := NewWriter(0, WithGrowableBuffer(), AsCSV(quoted))
+		w.AppendString(first, 0, AlignLeft)
+		w.AppendString(second, 0, AlignLeft)
+
+		r := csv.NewReader(strings.NewReader(w.String()))
+		r.FieldsPerRecord = 2
+		got, err := r.Read()
+		if err != nil {
+			t.Fatalf("%q: %v", w.String(), err)
+		}
+		if got[0] != first || got[1] != second {
+			t.Fatalf("%q: want %q, got %q", w.String(), []string{first, second}, got)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package linewriter
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+type Locale struct {
+	Decimal  string
+	Group    string
+	Grouping []int
+
+	PercentPrefix string
+	PercentSuffix string
+
+	Yes   string
+	No    string
+	On    string
+	Off   string
+	True  string
+	False string
+
+	Months      [12]string
+	ShortMonths [12]string
+	Days        [7]string
+	ShortDays   [7]string
+}
+
+var (
+	English = Locale{
+		Decimal:       ".",
+		Group:         ",",
+		Grouping:      []int{3},
+		PercentSuffix: "%",
+		Yes:           "yes",
+		No:            "no",
+		On:            "on",
+		Off:           "off",
+		True:          "true",
+		False:         "false",
+		Months: [12]string{
+			"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December",
+		},
+		ShortMonths: [12]string{
+			"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+			"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+		},
+		Days:      [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		ShortDays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	}
+	French = Locale{
+		Decimal:       ",",
+		Group:         "\u202f",
+		Grouping:      []int{3},
+		PercentSuffix: "\u00a0%",
+		Yes:           "oui",
+		No:            "non",
+		On:            "activé",
+		Off:           "désactivé",
+		True:          "vrai",
+		False:         "faux",
+		Months: [12]string{
+			"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+		},
+		ShortMonths: [12]string{
+			"janv.", "févr.", "mars", "avr.", "mai", "juin",
+			"juil.", "août", "sept.", "oct.", "nov.", "déc.",
+		},
+		Days:      [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		ShortDays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	}
+	German = Locale{
+		Decimal:       ",",
+		Group:         ".",
+		Grouping:      []int{3},
+		PercentSuffix: "\u00a0%",
+		Yes:           "ja",
+		No:            "nein",
+		On:            "an",
+		Off:           "aus",
+		True:          "wahr",
+		False:         "falsch",
+		Months: [12]string{
+			"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember",
+		},
+		ShortMonths: [12]string{
+			"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni",
+			"Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez.",
+		},
+		Days:      [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		ShortDays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+	}
+	Japanese = Locale{
+		Decimal:       ".",
+		Group:         ",",
+		Grouping:      []int{3},
+		PercentSuffix: "%",
+		Yes:           "はい",
+		No:            "いいえ",
+		On:            "オン",
+		Off:           "オフ",
+		True:          "真",
+		False:         "偽",
+		Months: [12]string{
+			"1月", "2月", "3月", "4月", "5月", "6月",
+			"7月", "8月", "9月", "10月", "11月", "12月",
+		},
+		ShortMonths: [12]string{
+			"1月", "2月", "3月", "4月", "5月", "6月",
+			"7月", "8月", "9月", "10月", "11月", "12月",
+		},
+		Days:      [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		ShortDays: [7]string{"日", "月", "火", "水", "木", "金", "土"},
+	}
+)
+
+func WithLocale(loc Locale) Option {
+	return func(w *Writer) {
+		w.locale = &loc
+		if loc.Group != "" {
+			w.groupsep = append(w.groupsep[:0], loc.Group...)
+		}
+		if len(loc.Grouping) > 0 {
+			WithGroupSeparator(string(w.groupsep), loc.Grouping...)(w)
+		}
+	}
+}
+
+func (w *Writer) localizeDecimal() {
+	if w.locale == nil || w.locale.Decimal == "" || w.locale.Decimal == "." || w.mode == modeJSON {
+		return
+	}
+	i := bytes.IndexByte(w.tmp, '.')
+	if i < 0 {
+		return
+	}
+	w.scratch = append(w.scratch[:0], w.tmp[i+1:]...)
+	w.tmp = append(w.tmp[:i], w.locale.Decimal...)
+	w.tmp = append(w.tmp, w.scratch...)
+}
+
+func (w *Writer) appendPercent() {
+	if w.locale == nil || (w.locale.PercentPrefix == "" && w.locale.PercentSuffix == "") {
+		w.tmp = append(w.tmp, '%')
+		return
+	}
+	if prefix := w.locale.PercentPrefix; prefix != "" {
+		w.scratch = append(w.scratch[:0], w.tmp...)
+		w.tmp = append(w.tmp[:0], prefix...)
+		w.tmp = append(w.tmp, w.scratch...)
+	}
+	w.tmp = append(w.tmp, w.locale.PercentSuffix...)
+}
+
+func (w *Writer) boolWords(flag Flag) (string, string) {
+	var tval, fval string
+	if set := flag & YesNo; set != 0 {
+		tval, fval = "yes", "no"
+		if w.locale != nil && w.locale.Yes != "" {
+			tval, fval = w.locale.Yes, w.locale.No
+		}
+	} else if set := flag & OnOff; set != 0 {
+		tval, fval = "on", "off"
+		if w.locale != nil && w.locale.On != "" {
+			tval, fval = w.locale.On, w.locale.Off
+		}
+	} else if set := flag & OneZero; set != 0 {
+		tval, fval = "1", "0"
+	} else {
+		tval, fval = "true", "false"
+		if w.locale != nil && w.locale.True != "" {
+			tval, fval = w.locale.True, w.locale.False
+		}
+	}
+	return tval, fval
+}
+
+func (w *Writer) appendLocalTime(t time.Time, format string) {
+	names := [...]struct {
+		Layout string
+		Value  string
+	}{
+		{Layout: "January", Value: w.locale.Months[t.Month()-1]},
+		{Layout: "Jan", Value: w.locale.ShortMonths[t.Month()-1]},
+		{Layout: "Monday", Value: w.locale.Days[t.Weekday()]},
+		{Layout: "Mon", Value: w.locale.ShortDays[t.Weekday()]},
+	}
+	for len(format) > 0 {
+		var (
+			at     = -1
+			layout string
+			value  string
+		)
+		for _, n := range names {
+			i := strings.Index(format, n.Layout)
+			if i < 0 || (at >= 0 && i >= at) {
+				continue
+			}
+			at, layout, value = i, n.Layout, n.Value
+		}
+		if at < 0 {
+			break
+		}
+		w.tmp = t.AppendFormat(w.tmp, format[:at])
+		if value != "" {
+			w.tmp = append(w.tmp, value...)
+		} else {
+			w.tmp = t.AppendFormat(w.tmp, layout)
+		}
+		format = format[at+len(layout):]
+	}
+	w.tmp = t.AppendFormat(w.tmp, format)
+}
@@ -0,0 +1,59 @@
+package linewriter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocale(t *testing.T) {
+	when := time.Date(2019, 6, 11, 12, 25, 43, 0, time.UTC)
+	data := []struct {
+		Locale Locale
+		Append func(*Writer)
+		Want   string
+	}{
+		{Locale: English, Append: func(w *Writer) { w.AppendFloat(1234.5, 0, 2, Float|WithGrouping) }, Want: "1,234.5"},
+		{Locale: French, Append: func(w *Writer) { w.AppendFloat(1234.5, 0, 2, Float|WithGrouping) }, Want: "1\u202f234,5"},
+		{Locale: German, Append: func(w *Writer) { w.AppendFloat(1234.5, 0, 2, Float|WithGrouping) }, Want: "1.234,5"},
+		{Locale: Japanese, Append: func(w *Writer) { w.AppendFloat(1234.5, 0, 2, Float|WithGrouping) }, Want: "1,234.5"},
+		{Locale: German, Append: func(w *Writer) { w.AppendFloat(-1234567.25, 0, 2, Float|WithGrouping|WithZero) }, Want: "-1.234.567,25"},
+		{Locale: German, Append: func(w *Writer) { w.AppendFloat(1234.5, 0, 2, Float) }, Want: "1234,5"},
+		{Locale: German, Append: func(w *Writer) { w.AppendInt(1234567, 0, WithGrouping) }, Want: "1.234.567"},
+		{Locale: English, Append: func(w *Writer) { w.AppendPercent(0.9845, 0, 2, 0) }, Want: "98.45%"},
+		{Locale: French, Append: func(w *Writer) { w.AppendPercent(0.9845, 0, 2, 0) }, Want: "98,45\u00a0%"},
+		{Locale: German, Append: func(w *Writer) { w.AppendPercent(12.5, 0, 2, WithGrouping) }, Want: "1.250\u00a0%"},
+		{Locale: Locale{PercentPrefix: "%"}, Append: func(w *Writer) { w.AppendPercent(0.5, 0, 2, 0) }, Want: "%50"},
+		{Locale: Locale{}, Append: func(w *Writer) { w.AppendPercent(0.5, 0, 2, 0) }, Want: "50%"},
+		{Locale: French, Append: func(w *Writer) { w.AppendSize(1536, 0, SizeIEC) }, Want: "1,5K"},
+		{Locale: French, Append: func(w *Writer) { w.AppendBool(true, 0, YesNo) }, Want: "oui"},
+		{Locale: French, Append: func(w *Writer) { w.AppendBool(false, 0, OnOff) }, Want: "désactivé"},
+		{Locale: German, Append: func(w *Writer) { w.AppendBool(false, 0, TrueFalse) }, Want: "falsch"},
+		{Locale: Japanese, Append: func(w *Writer) { w.AppendBool(true, 0, YesNo) }, Want: "はい"},
+		{Locale: Japanese, Append: func(w *Writer) { w.AppendBool(true, 0, OneZero) }, Want: "1"},
+		{Locale: Locale{}, Append: func(w *Writer) { w.AppendBool(true, 0, OnOff) }, Want: "on"},
+		{Locale: French, Append: func(w *Writer) { w.AppendTime(when, "Monday 2 January 2006", 0) }, Want: "mardi 11 juin 2019"},
+		{Locale: German, Append: func(w *Writer) { w.AppendTime(when, "Mon, 02. Jan 2006 15:04", 0) }, Want: "Di., 11. Juni 2019 12:25"},
+		{Locale: Japanese, Append: func(w *Writer) { w.AppendTime(when, "2006年January2日 (Mon)", 0) }, Want: "2019年6月11日 (火)"},
+		{Locale: Locale{}, Append: func(w *Writer) { w.AppendTime(when, "Mon Jan _2 2006", 0) }, Want: "Tue Jun 11 2019"},
+	}
+	for i, d := range data {
+		w := NewWriter(256, WithFlag(DefaultFlags|NoPadding), WithLocale(d.Locale))
+		d.Append(w)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func TestLocaleJSON(t *testing.T) {
+	w := NewWriter(256, WithLocale(German), AsJSON("v", "p", "u", "s"))
+	w.AppendFloat(1234.5, 10, 1, AlignRight|Float|WithGrouping)
+	w.AppendInt(1234567, 10, AlignRight|WithGrouping)
+	w.AppendUint(1234567, 10, AlignRight|WithGrouping)
+	w.AppendFloat(0.25, 10, -1, AlignRight|Significant)
+
+	want := `{"v":1234.5,"p":1234567,"u":1234567,"s":0.25}`
+	if got := w.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
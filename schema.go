@@ -0,0 +1,180 @@
+package linewriter
+
+import (
+	"time"
+)
+
+type Kind uint8
+
+const (
+	KindString Kind = iota
+	KindBytes
+	KindInt
+	KindUint
+	KindFloat
+	KindPercent
+	KindBool
+	KindTime
+	KindDuration
+	KindSize
+)
+
+type Column struct {
+	Name   string
+	Width  int
+	Prec   int
+	Format string
+	Flag   Flag
+	Kind   Kind
+}
+
+func WithSchema(cols ...Column) Option {
+	return func(w *Writer) {
+		w.schema = w.schema[:0]
+		for _, c := range cols {
+			if c.Kind == KindTime && c.Width == 0 {
+				c.Width = displayWidth([]byte(time.Time{}.Format(c.Format)))
+			}
+			if n := displayWidth([]byte(c.Name)); n > c.Width {
+				c.Width = n
+			}
+			w.schema = append(w.schema, c)
+		}
+	}
+}
+
+func (w *Writer) AppendHeader() {
+	for _, c := range w.schema {
+		w.AppendString(c.Name, c.Width, c.Flag)
+	}
+}
+
+func (w *Writer) AppendRecord(values ...interface{}) {
+	if len(values) != len(w.schema) {
+		w.setError(ErrRecord)
+	}
+	for i, c := range w.schema {
+		var v interface{}
+		if i < len(values) {
+			v = values[i]
+		}
+		if !w.appendColumn(c, v) {
+			w.setError(ErrKind)
+			w.AppendString("", c.Width, c.Flag)
+		}
+	}
+}
+
+func (w *Writer) appendColumn(c Column, v interface{}) bool {
+	switch c.Kind {
+	case KindString:
+		str, ok := v.(string)
+		if ok {
+			w.AppendString(str, c.Width, c.Flag)
+		}
+		return ok
+	case KindBytes:
+		bs, ok := v.([]byte)
+		if ok {
+			w.AppendBytes(bs, c.Width, c.Flag)
+		}
+		return ok
+	case KindInt:
+		i, ok := toInt(v)
+		if ok {
+			w.AppendInt(i, c.Width, c.Flag)
+		}
+		return ok
+	case KindUint:
+		u, ok := toUint(v)
+		if ok {
+			w.AppendUint(u, c.Width, c.Flag)
+		}
+		return ok
+	case KindSize:
+		i, ok := toInt(v)
+		if !ok {
+			var u uint64
+			if u, ok = toUint(v); ok {
+				i = int64(u)
+			}
+		}
+		if ok {
+			w.AppendSize(i, c.Width, c.Flag)
+		}
+		return ok
+	case KindFloat, KindPercent:
+		f, ok := toFloat(v)
+		if ok && c.Kind == KindPercent {
+			w.AppendPercent(f, c.Width, c.Prec, c.Flag)
+		} else if ok {
+			w.AppendFloat(f, c.Width, c.Prec, c.Flag)
+		}
+		return ok
+	case KindBool:
+		b, ok := v.(bool)
+		if ok {
+			w.AppendBool(b, c.Width, c.Flag)
+		}
+		return ok
+	case KindTime:
+		t, ok := v.(time.Time)
+		if ok {
+			w.appendTime(t, c.Format, c.Width, c.Flag)
+		}
+		return ok
+	case KindDuration:
+		d, ok := v.(time.Duration)
+		if ok {
+			w.AppendDuration(d, c.Width, c.Flag)
+		}
+		return ok
+	default:
+		return false
+	}
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch i := v.(type) {
+	case int:
+		return int64(i), true
+	case int8:
+		return int64(i), true
+	case int16:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	default:
+		return 0, false
+	}
+}
+
+func toUint(v interface{}) (uint64, bool) {
+	switch u := v.(type) {
+	case uint:
+		return uint64(u), true
+	case uint8:
+		return uint64(u), true
+	case uint16:
+		return uint64(u), true
+	case uint32:
+		return uint64(u), true
+	case uint64:
+		return u, true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch f := v.(type) {
+	case float32:
+		return float64(f), true
+	case float64:
+		return f, true
+	default:
+		return 0, false
+	}
+}
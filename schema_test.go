@@ -0,0 +1,83 @@
+package linewriter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+var columns = []Column{
+	{Name: "id", Width: 4, Flag: AlignLeft, Kind: KindUint},
+	{Name: "name", Width: 10, Flag: AlignLeft, Kind: KindString},
+	{Name: "state", Width: 3, Flag: AlignCenter | OnOff, Kind: KindBool},
+	{Name: "ratio", Width: 6, Prec: 1, Flag: AlignRight, Kind: KindPercent},
+	{Name: "when", Format: "15:04:05", Flag: AlignLeft, Kind: KindTime},
+	{Name: "elapsed", Width: 6, Flag: AlignRight, Kind: KindDuration},
+}
+
+func ExampleWriter_AppendHeader() {
+	when := time.Date(2019, 6, 11, 12, 25, 43, 0, time.UTC)
+
+	w := NewWriter(256, WithSeparator([]byte(" | ")), WithSchema(columns...))
+	w.AppendHeader()
+	fmt.Println(w.String())
+	w.Reset()
+
+	w.AppendRecord(uint64(1), "playback", false, 0.9845, when, 12*time.Second)
+	fmt.Println(w.String())
+	w.Reset()
+
+	w.AppendRecord(uint(2), "record", true, 0.5, when.Add(time.Minute), 3*time.Minute)
+	fmt.Println(w.String())
+
+	// Output:
+	// id   | name       | state |  ratio | when     | elapsed
+	// 1    | playback   |  off  |  98.5% | 12:25:43 |     12s
+	// 2    | record     |  on   |    50% | 12:26:43 |   3m00s
+}
+
+func TestAppendRecord(t *testing.T) {
+	when := time.Date(2019, 6, 11, 12, 25, 43, 0, time.UTC)
+	data := []struct {
+		Values []interface{}
+		Want   error
+	}{
+		{Values: []interface{}{uint8(1), "playback", true, 0.5, when, time.Second}, Want: nil},
+		{Values: []interface{}{1, "playback", true, 0.5, when, time.Second}, Want: ErrKind},
+		{Values: []interface{}{uint8(1), []byte("playback"), true, 0.5, when, time.Second}, Want: ErrKind},
+		{Values: []interface{}{uint8(1), "playback", true, 1, when, time.Second}, Want: ErrKind},
+		{Values: []interface{}{uint8(1), "playback", true, 0.5, when, int64(time.Second)}, Want: ErrKind},
+		{Values: []interface{}{uint8(1), "playback", true, 0.5, when}, Want: ErrRecord},
+		{Values: []interface{}{uint8(1), "playback", true, 0.5, when, time.Second, 0}, Want: ErrRecord},
+	}
+	header := NewWriter(256, append(defaults, WithSchema(columns...))...)
+	header.AppendHeader()
+
+	for i, d := range data {
+		w := NewWriter(256, append(defaults, WithSchema(columns...))...)
+		w.AppendRecord(d.Values...)
+		if err := w.Err(); err != d.Want {
+			t.Errorf("%d: want %v, got %v", i+1, d.Want, err)
+		}
+		if d.Want == ErrRecord {
+			continue
+		}
+		if want, got := len(header.String()), len(w.String()); want != got {
+			t.Errorf("%d: record not aligned with header (%d != %d)", i+1, want, got)
+		}
+	}
+}
+
+func TestSchemaNames(t *testing.T) {
+	w := NewWriter(256, AsJSON(), WithSchema(columns[:2]...))
+	w.AppendRecord(uint64(1), "playback")
+	if want, got := `{"id":1,"name":"playback"}`, w.String(); want != got {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	w = NewWriter(256, WithSchema(columns[:2]...), AsCSV(false))
+	w.AppendHeader()
+	if want, got := `id,name`, w.String(); want != got {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
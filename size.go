@@ -0,0 +1,74 @@
+package linewriter
+
+import (
+	"math"
+	"strconv"
+)
+
+const sizeUnits = "KMGTPEZY"
+
+func appendSize(dst []byte, v int64, prec int, flag Flag) []byte {
+	var (
+		base  = 1000.0
+		iec   = flag&SizeIEC != 0
+		bits  = flag&SizeBits != 0
+		long  = flag&SizeLong != 0
+		n     = float64(v)
+		start = len(dst)
+		unit  = -1
+	)
+	if iec {
+		base = 1024
+	}
+	if bits {
+		n *= 8
+	}
+	for unit < len(sizeUnits)-1 && math.Abs(n) >= base {
+		n /= base
+		unit++
+	}
+	if unit >= 0 && unit < len(sizeUnits)-1 && roundsTo(n, prec, base) {
+		n /= base
+		unit++
+	}
+	if unit < 0 {
+		dst = strconv.AppendFloat(dst, n, 'f', 0, 64)
+	} else {
+		dst = strconv.AppendFloat(dst, n, 'f', prec, 64)
+		if set := flag & WithZero; set == 0 {
+			dst = dst[:start+len(trimFraction(dst[start:]))]
+		}
+	}
+	if unit < 0 && !long && !bits {
+		return dst
+	}
+	if set := flag & WithUnitSpace; set != 0 {
+		dst = append(dst, ' ')
+	}
+	if unit >= 0 {
+		if c := sizeUnits[unit]; long && !iec && c == 'K' {
+			dst = append(dst, 'k')
+		} else {
+			dst = append(dst, c)
+		}
+		if long && iec {
+			dst = append(dst, 'i')
+		}
+	}
+	switch {
+	case long && bits:
+		dst = append(dst, "bit"...)
+	case long:
+		dst = append(dst, 'B')
+	case bits:
+		dst = append(dst, 'b')
+	}
+	return dst
+}
+
+func (w *Writer) sizePrecision(prec int) int {
+	if w.sizeprec >= 0 {
+		return w.sizeprec
+	}
+	return prec
+}
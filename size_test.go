@@ -0,0 +1,157 @@
+package linewriter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAppendSize(t *testing.T) {
+	data := []struct {
+		Value int64
+		Prec  int
+		Flags Flag
+		Want  string
+	}{
+		{Value: 0, Prec: 2, Flags: SizeIEC, Want: "0"},
+		{Value: 0, Prec: 2, Flags: SizeIEC | SizeLong, Want: "0B"},
+		{Value: 0, Prec: 2, Flags: SizeIEC | SizeLong | WithUnitSpace, Want: "0 B"},
+		{Value: 512, Prec: 2, Flags: SizeIEC, Want: "512"},
+		{Value: 512, Prec: 2, Flags: SizeIEC | WithUnitSpace, Want: "512"},
+		{Value: 512, Prec: 2, Flags: SizeIEC | WithZero, Want: "512"},
+		{Value: 1000, Prec: 2, Flags: SizeIEC, Want: "1000"},
+		{Value: 1000, Prec: 2, Flags: SizeSI, Want: "1K"},
+		{Value: 1023, Prec: 2, Flags: SizeIEC, Want: "1023"},
+		{Value: 1024, Prec: 2, Flags: SizeIEC, Want: "1K"},
+		{Value: 1024, Prec: 2, Flags: SizeIEC | WithZero, Want: "1.00K"},
+		{Value: 1024, Prec: 2, Flags: SizeSI, Want: "1.02K"},
+		{Value: 1536, Prec: 2, Flags: SizeIEC, Want: "1.5K"},
+		{Value: 1536, Prec: 0, Flags: SizeIEC, Want: "2K"},
+		{Value: 1536, Prec: 3, Flags: SizeIEC | WithZero, Want: "1.500K"},
+		{Value: 1536, Prec: 2, Flags: SizeIEC | SizeLong, Want: "1.5KiB"},
+		{Value: 1536, Prec: 2, Flags: SizeIEC | SizeLong | WithUnitSpace, Want: "1.5 KiB"},
+		{Value: 1536, Prec: 2, Flags: SizeSI | SizeLong, Want: "1.54kB"},
+		{Value: 1536, Prec: 2, Flags: SizeSI | SizeLong | WithUnitSpace, Want: "1.54 kB"},
+		{Value: 1536, Prec: 2, Flags: SizeIEC | WithUnitSpace, Want: "1.5 K"},
+		{Value: 1<<20 - 1, Prec: 2, Flags: SizeIEC, Want: "1M"},
+		{Value: 1<<20 - 1, Prec: 2, Flags: SizeIEC | SizeLong, Want: "1MiB"},
+		{Value: 999999, Prec: 1, Flags: SizeSI | SizeLong, Want: "1MB"},
+		{Value: 1 << 20, Prec: 2, Flags: SizeIEC, Want: "1M"},
+		{Value: 5 << 30, Prec: 2, Flags: SizeIEC | SizeLong, Want: "5GiB"},
+		{Value: 5e9, Prec: 2, Flags: SizeSI | SizeLong, Want: "5GB"},
+		{Value: 1 << 40, Prec: 2, Flags: SizeIEC | SizeLong, Want: "1TiB"},
+		{Value: 1 << 50, Prec: 2, Flags: SizeIEC | SizeLong, Want: "1PiB"},
+		{Value: 1 << 60, Prec: 2, Flags: SizeIEC | SizeLong, Want: "1EiB"},
+		{Value: 1<<63 - 1, Prec: 2, Flags: SizeIEC, Want: "8E"},
+		{Value: 1<<63 - 1, Prec: 2, Flags: SizeSI | SizeLong, Want: "9.22EB"},
+		{Value: -1536, Prec: 2, Flags: SizeIEC, Want: "-1.5K"},
+		{Value: -512, Prec: 2, Flags: SizeIEC | SizeLong, Want: "-512B"},
+		{Value: 64, Prec: 2, Flags: SizeSI | SizeBits, Want: "512b"},
+		{Value: 64, Prec: 2, Flags: SizeSI | SizeBits | SizeLong, Want: "512bit"},
+		{Value: 125, Prec: 2, Flags: SizeSI | SizeBits, Want: "1Kb"},
+		{Value: 125, Prec: 2, Flags: SizeSI | SizeBits | SizeLong | WithUnitSpace, Want: "1 kbit"},
+		{Value: 128, Prec: 2, Flags: SizeIEC | SizeBits | SizeLong, Want: "1Kibit"},
+		{Value: 12300000, Prec: 1, Flags: SizeSI | SizeBits | SizeLong | WithUnitSpace, Want: "98.4 Mbit"},
+		{Value: 1<<63 - 1, Prec: 2, Flags: SizeIEC | SizeBits, Want: "64Eb"},
+	}
+	for i, d := range data {
+		got := string(appendSize(nil, d.Value, d.Prec, d.Flags))
+		if got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func TestSizePrecision(t *testing.T) {
+	data := []struct {
+		Options []Option
+		Append  func(*Writer)
+		Want    string
+	}{
+		{
+			Append: func(w *Writer) { w.AppendSize(1234567, 0, SizeSI) },
+			Want:   "1.23M",
+		},
+		{
+			Options: []Option{WithSizePrecision(0)},
+			Append:  func(w *Writer) { w.AppendSize(1234567, 0, SizeSI) },
+			Want:    "1M",
+		},
+		{
+			Options: []Option{WithSizePrecision(4)},
+			Append:  func(w *Writer) { w.AppendSize(1234567, 0, SizeSI) },
+			Want:    "1.2346M",
+		},
+		{
+			Append: func(w *Writer) { w.AppendRate(1234567, 1e9, 0, SizeSI) },
+			Want:   "1.2 MB/s",
+		},
+		{
+			Options: []Option{WithSizePrecision(3)},
+			Append:  func(w *Writer) { w.AppendRate(1234567, 1e9, 0, SizeSI) },
+			Want:    "1.235 MB/s",
+		},
+		{
+			Options: []Option{WithLocale(French), WithSizePrecision(1)},
+			Append:  func(w *Writer) { w.AppendSize(1536, 0, SizeIEC|SizeLong|WithUnitSpace) },
+			Want:    "1,5 KiB",
+		},
+	}
+	for i, d := range data {
+		options := append([]Option{WithFlag(NoPadding)}, d.Options...)
+		w := NewWriter(256, options...)
+		d.Append(w)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func TestAppendSizeFlags(t *testing.T) {
+	data := []struct {
+		Flags Flag
+		Want  string
+	}{
+		{Flags: 0, Want: "1.46K"},
+		{Flags: SizeIEC | SizeLong, Want: "1.46KiB"},
+		{Flags: SizeSI | SizeLong, Want: "1.5kB"},
+		{Flags: DefaultFlags | SizeSI | SizeLong, Want: "1.5kB"},
+		{Flags: SizeSI | SizeBits | SizeLong, Want: "12kbit"},
+	}
+	for i, d := range data {
+		w := NewWriter(256, WithFlag(DefaultFlags|NoPadding))
+		w.AppendSize(1500, 0, d.Flags)
+		if err := w.Err(); err != nil {
+			t.Errorf("%d: unexpected error: %v", i+1, err)
+		}
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func FuzzAppendSize(f *testing.F) {
+	f.Add(int64(1536), 6, uint64(AlignRight|SizeIEC))
+	f.Add(int64(-1<<62), 12, uint64(AlignLeft|SizeSI|SizeLong|WithUnitSpace))
+	f.Add(int64(math.MaxInt64), 3, uint64(SizeBits|SizeIEC|WithZero))
+	f.Fuzz(func(t *testing.T, v int64, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendSize(v, width, flag)
+		})
+
+		str := string(appendSize(nil, v, 2, SizeIEC))
+		exp := strings.IndexByte(sizeUnits, str[len(str)-1]) + 1
+		if exp > 0 {
+			str = str[:len(str)-1]
+		}
+		got, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			t.Fatalf("%q: %v", str, err)
+		}
+		got *= math.Pow(1024, float64(exp))
+		if diff := math.Abs(got - float64(v)); diff > math.Abs(float64(v))*0.005 {
+			t.Fatalf("%q: want %d, got %f", str, v, got)
+		}
+	})
+}
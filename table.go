@@ -0,0 +1,527 @@
+package linewriter
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+type Border struct {
+	Horizontal string
+	Vertical   string
+
+	TopLeft  string
+	TopMid   string
+	TopRight string
+
+	MidLeft  string
+	MidMid   string
+	MidRight string
+
+	BottomLeft  string
+	BottomMid   string
+	BottomRight string
+}
+
+var (
+	BorderBox = Border{
+		Horizontal:  "─",
+		Vertical:    "│",
+		TopLeft:     "┌",
+		TopMid:      "┬",
+		TopRight:    "┐",
+		MidLeft:     "├",
+		MidMid:      "┼",
+		MidRight:    "┤",
+		BottomLeft:  "└",
+		BottomMid:   "┴",
+		BottomRight: "┘",
+	}
+	BorderASCII = Border{
+		Horizontal:  "-",
+		Vertical:    "|",
+		TopLeft:     "+",
+		TopMid:      "+",
+		TopRight:    "+",
+		MidLeft:     "+",
+		MidMid:      "+",
+		MidRight:    "+",
+		BottomLeft:  "+",
+		BottomMid:   "+",
+		BottomRight: "+",
+	}
+)
+
+type TableOption func(*Table)
+
+func WithBorder(b Border) TableOption {
+	return func(t *Table) {
+		t.border = &b
+	}
+}
+
+func WithHeaders(names ...string) TableOption {
+	return func(t *Table) {
+		t.headers = append(t.headers[:0], names...)
+	}
+}
+
+func WithWrap() TableOption {
+	return func(t *Table) {
+		t.wrap = true
+	}
+}
+
+func WithMaxWidth(widths ...int) TableOption {
+	return func(t *Table) {
+		t.limits = append(t.limits[:0], widths...)
+	}
+}
+
+type Table struct {
+	writer *Writer
+	format *Writer
+
+	headers []string
+	header  []cell
+	limits  []int
+	widths  []int
+	numbers []int
+	border  *Border
+	rule    []byte
+	wrap    bool
+	pending bytes.Buffer
+
+	data  []byte
+	cells []cell
+	rows  [][]cell
+	start int
+	err   error
+
+	overflow bool
+
+	parts [][][]byte
+	line  []cell
+}
+
+func NewTable(w *Writer, options ...TableOption) *Table {
+	t := Table{
+		writer: w,
+		format: NewWriter(0, WithGrowableBuffer(), WithFlag(w.flags&^(NoSpace|WithQuote)|NoPadding)),
+	}
+	for i := 0; i < len(options); i++ {
+		options[i](&t)
+	}
+	t.format.locale = w.locale
+	t.format.groupsep = append(t.format.groupsep[:0], w.groupsep...)
+	t.format.groups = append(t.format.groups[:0], w.groups...)
+	t.format.sizeprec = w.sizeprec
+	t.format.autoexp = w.autoexp
+
+	for _, h := range t.headers {
+		t.header = append(t.header, cell{data: []byte(h)})
+	}
+	if w.mode == modeMarkdown && len(w.headers) == 0 {
+		w.headers = append(w.headers, t.headers...)
+	}
+	if w.isKeyed() && len(w.names) == 0 {
+		w.names = append(w.names, t.headers...)
+	}
+	return &t
+}
+
+func (t *Table) Err() error {
+	return t.err
+}
+
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+func (t *Table) AppendString(str string, flag Flag) {
+	t.format.AppendString(str, 0, cellFlag(flag))
+	t.appendCell(flag, false)
+}
+
+func (t *Table) AppendBytes(bs []byte, flag Flag) {
+	t.format.AppendBytes(bs, 0, cellFlag(flag))
+	t.appendCell(flag, false)
+}
+
+func (t *Table) AppendTime(when time.Time, format string, flag Flag) {
+	t.format.AppendTime(when, format, cellFlag(flag))
+	t.appendCell(flag, false)
+}
+
+func (t *Table) AppendDuration(d time.Duration, flag Flag) {
+	t.format.AppendDuration(d, 0, cellFlag(flag))
+	t.appendCell(flag, false)
+}
+
+func (t *Table) AppendBool(b bool, flag Flag) {
+	t.format.AppendBool(b, 0, cellFlag(flag))
+	t.appendCell(flag, false)
+}
+
+func (t *Table) AppendPercent(v float64, prec int, flag Flag) {
+	t.format.AppendPercent(v, 0, prec, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) AppendFloat(v float64, prec int, flag Flag) {
+	t.format.AppendFloat(v, 0, prec, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) AppendSize(v int64, flag Flag) {
+	t.format.AppendSize(v, 0, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) AppendUnit(v float64, unit string, prec int, flag Flag) {
+	t.format.AppendUnit(v, unit, 0, prec, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) AppendRate(bytes int64, d time.Duration, flag Flag) {
+	t.format.AppendRate(bytes, d, 0, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) AppendInt(v int64, flag Flag) {
+	t.format.AppendInt(v, 0, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) AppendIntBits(v int64, bits int, flag Flag) {
+	t.format.AppendIntBits(v, bits, 0, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) AppendUint(v uint64, flag Flag) {
+	t.format.AppendUint(v, 0, cellFlag(flag))
+	t.appendCell(flag, true)
+}
+
+func (t *Table) EndRow() {
+	if len(t.cells) == t.start {
+		return
+	}
+	t.rows = append(t.rows, t.cells[t.start:len(t.cells):len(t.cells)])
+	t.start = len(t.cells)
+}
+
+func (t *Table) Reset() {
+	t.data = t.data[:0]
+	t.cells = t.cells[:0]
+	t.rows = t.rows[:0]
+	t.start = 0
+	t.err = nil
+	t.overflow = false
+}
+
+func (t *Table) Read(bs []byte) (int, error) {
+	if t.pending.Len() == 0 {
+		t.EndRow()
+		if len(t.rows) == 0 {
+			return 0, io.EOF
+		}
+		_, err := t.WriteTo(&t.pending)
+		if err == ErrOverflow {
+			n, _ := t.pending.Read(bs)
+			return n, err
+		}
+		if err != nil {
+			t.pending.Reset()
+			return 0, err
+		}
+	}
+	return t.pending.Read(bs)
+}
+
+func (t *Table) WriteTo(ws io.Writer) (int64, error) {
+	defer t.Reset()
+
+	t.EndRow()
+	if t.err != nil {
+		return 0, t.err
+	}
+	t.computeWidths()
+
+	var (
+		written int64
+		header  = len(t.header) > 0 && t.writer.mode != modeMarkdown && !t.writer.isKeyed()
+		empty   = len(t.rows) == 0 && !header
+	)
+	if t.border != nil && !empty {
+		n, err := t.writeRule(ws, t.border.TopLeft, t.border.TopMid, t.border.TopRight)
+		if written += n; err != nil {
+			return written, err
+		}
+	}
+	if header {
+		for i := range t.header {
+			t.header[i].flag = t.alignment(i)
+		}
+		n, err := t.writeRow(ws, t.header)
+		if written += n; err != nil {
+			return written, err
+		}
+		if t.border != nil {
+			n, err := t.writeRule(ws, t.border.MidLeft, t.border.MidMid, t.border.MidRight)
+			if written += n; err != nil {
+				return written, err
+			}
+		}
+	}
+	for _, row := range t.rows {
+		n, err := t.writeRow(ws, row)
+		if written += n; err != nil {
+			return written, err
+		}
+	}
+	if t.border != nil && !empty {
+		n, err := t.writeRule(ws, t.border.BottomLeft, t.border.BottomMid, t.border.BottomRight)
+		if written += n; err != nil {
+			return written, err
+		}
+	}
+	if t.overflow {
+		return written, ErrOverflow
+	}
+	return written, nil
+}
+
+func (t *Table) writeRow(ws io.Writer, row []cell) (int64, error) {
+	if !t.wrap {
+		return t.writeLine(ws, row)
+	}
+	for len(t.parts) < len(t.widths) {
+		t.parts = append(t.parts, nil)
+	}
+	var (
+		parts  = t.parts[:len(t.widths)]
+		height int
+	)
+	for i := range parts {
+		parts[i] = parts[i][:0]
+		if i < len(row) && row[i].number {
+			parts[i] = append(parts[i], row[i].data)
+		} else if i < len(row) {
+			parts[i] = appendWrap(parts[i], row[i].data, t.widths[i])
+		}
+		if len(parts[i]) > height {
+			height = len(parts[i])
+		}
+	}
+	var written int64
+	for len(t.line) < len(t.widths) {
+		t.line = append(t.line, cell{})
+	}
+	line := t.line[:len(t.widths)]
+	for j := 0; j < height || j == 0; j++ {
+		for i := range line {
+			line[i] = cell{flag: t.alignment(i)}
+			if i < len(row) {
+				line[i].flag = row[i].flag
+				line[i].number = row[i].number
+			}
+			if j < len(parts[i]) {
+				line[i].data = parts[i][j]
+			}
+		}
+		n, err := t.writeLine(ws, line)
+		if written += n; err != nil {
+			return written, err
+		}
+	}
+	if t.overflow {
+		return written, ErrOverflow
+	}
+	return written, nil
+}
+
+func (t *Table) writeLine(ws io.Writer, row []cell) (int64, error) {
+	for i := range t.widths {
+		if i < len(row) {
+			t.writeCell(i, row[i])
+		} else {
+			t.writeCell(i, cell{flag: t.alignment(i)})
+		}
+	}
+	if t.border != nil {
+		t.writeBorder(t.border.Vertical)
+	}
+	return t.flush(ws)
+}
+
+func (t *Table) writeRule(ws io.Writer, left, mid, right string) (int64, error) {
+	var pad int
+	if isWithPadding(t.writer.flags, 0) {
+		pad = displayWidth(t.writer.padding)
+	}
+	t.rule = append(t.rule[:0], left...)
+	for i, width := range t.widths {
+		if i > 0 {
+			t.rule = append(t.rule, mid...)
+		}
+		for j := 0; j < width+2*pad; j++ {
+			t.rule = append(t.rule, t.border.Horizontal...)
+		}
+	}
+	t.rule = append(t.rule, right...)
+	t.writer.AppendBytes(t.rule, 0, AlignLeft|NoPadding|NoSeparator)
+	return t.flush(ws)
+}
+
+func (t *Table) writeBorder(str string) {
+	t.writer.AppendString(str, 0, AlignLeft|NoPadding|NoSeparator)
+}
+
+func (t *Table) flush(ws io.Writer) (int64, error) {
+	n, err := t.writer.WriteTo(ws)
+	if err == ErrOverflow && !t.writer.strict {
+		t.overflow, err = true, nil
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+func (t *Table) writeCell(i int, c cell) {
+	var (
+		width = t.widths[i]
+		flag  = c.flag &^ Hex
+	)
+	if set := flag & truncateFlags; set == 0 && !c.number && !t.writer.isKeyed() && displayWidth(c.data) > width {
+		flag |= Truncate
+	}
+	if t.border != nil {
+		t.writeBorder(t.border.Vertical)
+		flag |= NoSeparator
+	}
+	if c.number {
+		t.writer.appendLeft(flag)
+		t.writer.appendNumber(c.data, width, flag)
+		return
+	}
+	t.writer.AppendBytes(c.data, width, flag)
+}
+
+func (t *Table) alignment(i int) Flag {
+	for _, row := range t.rows {
+		if i < len(row) {
+			return row[i].flag & alignFlags
+		}
+	}
+	return AlignLeft
+}
+
+func (t *Table) computeWidths() {
+	t.widths = t.widths[:0]
+	t.numbers = t.numbers[:0]
+	for i, h := range t.header {
+		t.widths = widen(t.widths, i, displayWidth(h.data))
+	}
+	for _, row := range t.rows {
+		for i, c := range row {
+			if c.number {
+				t.numbers = widen(t.numbers, i, displayWidth(c.data))
+			}
+			if !t.wrap {
+				t.widths = widen(t.widths, i, displayWidth(c.data))
+				continue
+			}
+			for rest := c.data; ; {
+				p, next, more := nextLine(rest)
+				t.widths = widen(t.widths, i, displayWidth(p))
+				if !more {
+					break
+				}
+				rest = next
+			}
+		}
+	}
+	for i, n := range t.limits {
+		if i >= len(t.widths) || n <= 0 {
+			continue
+		}
+		// numbers can not be cut: the column keeps the width of its
+		// widest number unless the writer marks them as overflowing.
+		if i < len(t.numbers) && t.writer.marker == 0 && t.numbers[i] > n {
+			n = t.numbers[i]
+		}
+		if t.widths[i] > n {
+			t.widths[i] = n
+		}
+	}
+}
+
+func widen(widths []int, i, n int) []int {
+	for len(widths) <= i {
+		widths = append(widths, 0)
+	}
+	if n > widths[i] {
+		widths[i] = n
+	}
+	return widths
+}
+
+func (t *Table) appendCell(flag Flag, number bool) {
+	if err := t.format.Err(); err != nil && t.err == nil {
+		t.err = err
+	}
+	start := len(t.data)
+	t.data = append(t.data, t.format.Bytes()...)
+	c := cell{
+		data:   t.data[start:len(t.data):len(t.data)],
+		flag:   flag,
+		number: number,
+	}
+	t.cells = append(t.cells, c)
+	t.format.Reset()
+}
+
+func nextLine(data []byte) ([]byte, []byte, bool) {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return data, nil, false
+	}
+	return data[:i], data[i+1:], true
+}
+
+func appendWrap(lines [][]byte, data []byte, width int) [][]byte {
+	for more := true; more; {
+		var p []byte
+		p, data, more = nextLine(data)
+		if len(p) == 0 {
+			lines = append(lines, p)
+			continue
+		}
+		for len(p) > 0 {
+			if displayWidth(p) <= width {
+				lines = append(lines, p)
+				break
+			}
+			head := cut(p, width)
+			if len(head) == 0 {
+				n, _ := nextCluster(p)
+				head = p[:n]
+			}
+			rest := p[len(head):]
+			if len(rest) > 0 && rest[0] != ' ' {
+				if i := bytes.LastIndexByte(head, ' '); i > 0 {
+					head, rest = head[:i], p[i:]
+				}
+			}
+			lines = append(lines, bytes.TrimRight(head, " "))
+			p = bytes.TrimLeft(rest, " ")
+		}
+	}
+	return lines
+}
+
+func cellFlag(flag Flag) Flag {
+	return flag&^(NoSpace|WithQuote) | NoPadding
+}
@@ -0,0 +1,442 @@
+package linewriter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleTable() {
+	t := NewTable(NewWriter(64, WithSeparator([]byte(" | "))), WithHeaders("id", "name", "elapsed", "ratio"))
+	t.AppendUint(1, AlignLeft)
+	t.AppendString("playback", AlignLeft)
+	t.AppendDuration(17*time.Hour+31*time.Minute, AlignRight)
+	t.AppendPercent(0.9845, 1, AlignRight)
+	t.EndRow()
+
+	t.AppendUint(1024, AlignLeft)
+	t.AppendString("rec", AlignLeft)
+	t.AppendDuration(3*time.Second, AlignRight)
+	t.AppendPercent(0.05, 1, AlignRight)
+	t.EndRow()
+
+	t.WriteTo(os.Stdout)
+
+	// Output:
+	// id   | name     |   elapsed | ratio
+	// 1    | playback | 17h31m00s | 98.5%
+	// 1024 | rec      |        3s |    5%
+}
+
+func TestTable(t *testing.T) {
+	data := []struct {
+		Name    string
+		Writer  []Option
+		Options []TableOption
+		Want    string
+	}{
+		{
+			Name: "auto",
+			Want: "_      1_|_hello world_|_0x10_\n_1048576_|_x          _|_ 0x3_\n_       _|_           _|_    _\n",
+		},
+		{
+			Name:    "headers",
+			Options: []TableOption{WithHeaders("id", "message", "hex", "extra")},
+			Want:    "_     id_|_message    _|_ hex_|_extra_\n_      1_|_hello world_|_0x10_|_     _\n_1048576_|_x          _|_ 0x3_|_     _\n_       _|_           _|_    _|_     _\n",
+		},
+		{
+			Name:    "max-width",
+			Options: []TableOption{WithMaxWidth(0, 5, 2)},
+			Want:    "_      1_|_hell…_|_0x10_\n_1048576_|_x    _|_ 0x3_\n_       _|_     _|_    _\n",
+		},
+		{
+			Name:    "max-width-marker",
+			Writer:  []Option{WithOverflowMarker('#')},
+			Options: []TableOption{WithMaxWidth(4, 5, 2)},
+			Want:    "_   1_|_hell…_|_##_\n_####_|_x    _|_##_\n_    _|_     _|_  _\n",
+		},
+	}
+	for _, d := range data {
+		options := append(append([]Option{}, defaults...), d.Writer...)
+		tb := NewTable(NewWriter(64, options...), d.Options...)
+		tb.AppendUint(1, AlignRight)
+		tb.AppendString("hello world", AlignLeft)
+		tb.AppendUint(16, AlignRight|Hex|WithPrefix)
+		tb.EndRow()
+		tb.AppendUint(1<<20, AlignRight)
+		tb.AppendString("x", AlignLeft)
+		tb.AppendUint(3, AlignRight|Hex|WithPrefix)
+		tb.EndRow()
+		tb.AppendString("", AlignLeft)
+
+		var buf bytes.Buffer
+		if _, err := tb.WriteTo(&buf); err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Name, err)
+			continue
+		}
+		if got := buf.String(); got != d.Want {
+			t.Errorf("%s: want %q, got %q", d.Name, d.Want, got)
+		}
+		if tb.Len() != 0 {
+			t.Errorf("%s: table not reset after WriteTo", d.Name)
+		}
+	}
+}
+
+func TestTableMarkdown(t *testing.T) {
+	tb := NewTable(NewWriter(64, AsMarkdown()), WithHeaders("name", "count"))
+	for i, n := range []string{"a", "playback", "rec"} {
+		tb.AppendString(n, AlignLeft)
+		tb.AppendInt(int64(i*500), AlignRight)
+		tb.EndRow()
+	}
+	var buf bytes.Buffer
+	if _, err := tb.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{
+		"| name     | count |",
+		"| :------- | ----: |",
+		"| a        |     0 |",
+		"| playback |   500 |",
+		"| rec      |  1000 |",
+	}
+	if got := strings.TrimSpace(buf.String()); got != strings.Join(want, "\n") {
+		t.Errorf("want %s, got %s", strings.Join(want, "\n"), got)
+	}
+}
+
+func TestTableKeyed(t *testing.T) {
+	data := []struct {
+		Name   string
+		Option Option
+		Want   string
+	}{
+		{Name: "json", Option: AsJSON(), Want: "{\"id\":1024,\"name\":\"playback\"}\n"},
+		{Name: "json-names", Option: AsJSON("ident", "label"), Want: "{\"ident\":1024,\"label\":\"playback\"}\n"},
+		{Name: "logfmt", Option: AsLogfmt(), Want: "id=1024 name=playback\n"},
+	}
+	for _, d := range data {
+		tb := NewTable(NewWriter(64, d.Option), WithHeaders("id", "name"), WithMaxWidth(1, 1))
+		tb.AppendUint(1024, AlignRight)
+		tb.AppendString("playback", AlignLeft)
+		tb.EndRow()
+
+		var buf bytes.Buffer
+		if _, err := tb.WriteTo(&buf); err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Name, err)
+			continue
+		}
+		if got := buf.String(); got != d.Want {
+			t.Errorf("%s: want %q, got %q", d.Name, d.Want, got)
+		}
+	}
+}
+
+func TestTableErr(t *testing.T) {
+	tb := NewTable(NewWriter(64, defaults...))
+	tb.AppendUint(1, AlignRight|Hex|Octal)
+	tb.AppendString("playback", AlignLeft)
+	if err := tb.Err(); err != ErrFlag {
+		t.Errorf("want %v, got %v", ErrFlag, err)
+	}
+	if _, err := tb.WriteTo(&bytes.Buffer{}); err != ErrFlag {
+		t.Errorf("want %v, got %v", ErrFlag, err)
+	}
+	if err := tb.Err(); err != nil {
+		t.Errorf("error not cleared after WriteTo: %s", err)
+	}
+}
+
+func TestTableOverflow(t *testing.T) {
+	fill := func(tb *Table) {
+		tb.AppendString("playback", AlignLeft)
+		tb.AppendString("recording", AlignLeft)
+		tb.EndRow()
+		tb.AppendString("rec", AlignLeft)
+		tb.AppendString("a", AlignLeft)
+		tb.EndRow()
+	}
+	tb := NewTable(NewWriter(12, defaults...))
+	fill(tb)
+	var buf bytes.Buffer
+	if _, err := tb.WriteTo(&buf); err != ErrOverflow {
+		t.Errorf("want %v, got %v", ErrOverflow, err)
+	}
+	if want := "_playback_|_\n_rec     _|_\n"; buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+
+	fill(tb)
+	if n, err := tb.Read(make([]byte, 64)); err != ErrOverflow || n != buf.Len() {
+		t.Errorf("Read: want %d bytes and %v, got %d and %v", buf.Len(), ErrOverflow, n, err)
+	}
+
+	tb = NewTable(NewWriter(12, append(defaults, WithStrictBuffer())...))
+	fill(tb)
+	if _, err := tb.WriteTo(&buf); err != ErrOverflow {
+		t.Errorf("strict: want %v, got %v", ErrOverflow, err)
+	}
+}
+
+func ExampleWithBorder() {
+	for _, b := range []Border{BorderBox, BorderASCII} {
+		t := NewTable(NewWriter(256, WithPadding([]byte(" "))), WithHeaders("id", "name", "ratio"), WithBorder(b))
+		t.AppendUint(1, AlignRight)
+		t.AppendString("playback", AlignLeft)
+		t.AppendPercent(0.9845, 1, AlignRight)
+		t.EndRow()
+		t.AppendUint(1024, AlignRight)
+		t.AppendString("rec", AlignCenter)
+		t.AppendPercent(0.05, 1, AlignRight)
+		t.EndRow()
+		t.WriteTo(os.Stdout)
+	}
+
+	// Output:
+	// ┌──────┬──────────┬───────┐
+	// │   id │ name     │ ratio │
+	// ├──────┼──────────┼───────┤
+	// │    1 │ playback │ 98.5% │
+	// │ 1024 │   rec    │    5% │
+	// └──────┴──────────┴───────┘
+	// +------+----------+-------+
+	// |   id | name     | ratio |
+	// +------+----------+-------+
+	// |    1 | playback | 98.5% |
+	// | 1024 |   rec    |    5% |
+	// +------+----------+-------+
+}
+
+func TestTableBorder(t *testing.T) {
+	tb := NewTable(NewWriter(64, WithSeparator([]byte("#"))), WithBorder(BorderASCII))
+	tb.AppendString("a", AlignLeft)
+	tb.AppendString("bc", AlignRight)
+	tb.EndRow()
+
+	var buf bytes.Buffer
+	if _, err := tb.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "+-+--+\n|a|bc|\n+-+--+\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	buf.Reset()
+	if _, err := tb.WriteTo(&buf); err != nil || buf.Len() != 0 {
+		t.Errorf("empty table: unexpected output %q (%v)", buf.String(), err)
+	}
+}
+
+func TestTableWidth(t *testing.T) {
+	tb := NewTable(NewWriter(256, WithSeparator([]byte("|"))), WithMaxWidth(5))
+	tb.AppendString("日本語です", AlignLeft)
+	tb.AppendString("x", AlignLeft)
+	tb.EndRow()
+	tb.AppendString("abc", AlignRight)
+	tb.AppendString("👩‍💻", AlignLeft)
+	tb.EndRow()
+
+	var buf bytes.Buffer
+	if _, err := tb.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "日本…|x \n  abc|👩‍💻\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func ExampleWithWrap() {
+	t := NewTable(NewWriter(256, WithPadding([]byte(" "))), WithBorder(BorderASCII), WithMaxWidth(0, 16), WithWrap())
+	t.AppendUint(1, AlignRight)
+	t.AppendString("the quick brown fox jumps over the lazy dog", AlignLeft)
+	t.AppendString("done", AlignCenter)
+	t.EndRow()
+	t.AppendUint(42, AlignRight)
+	t.AppendString("multi\nline", AlignLeft)
+	t.AppendString("wip", AlignCenter)
+	t.EndRow()
+	t.WriteTo(os.Stdout)
+
+	// Output:
+	// +----+------------------+------+
+	// |  1 | the quick brown  | done |
+	// |    | fox jumps over   |      |
+	// |    | the lazy dog     |      |
+	// | 42 | multi            | wip  |
+	// |    | line             |      |
+	// +----+------------------+------+
+}
+
+func TestWrap(t *testing.T) {
+	data := []struct {
+		Value string
+		Width int
+		Want  []string
+	}{
+		{Value: "", Width: 5, Want: []string{""}},
+		{Value: "hello", Width: 5, Want: []string{"hello"}},
+		{Value: "hello world", Width: 5, Want: []string{"hello", "world"}},
+		{Value: "hello world", Width: 8, Want: []string{"hello", "world"}},
+		{Value: "a b c d e f", Width: 3, Want: []string{"a b", "c d", "e f"}},
+		{Value: "playback", Width: 3, Want: []string{"pla", "yba", "ck"}},
+		{Value: "one\n\ntwo", Width: 10, Want: []string{"one", "", "two"}},
+		{Value: "日本語です", Width: 5, Want: []string{"日本", "語で", "す"}},
+		{Value: "日本語です", Width: 1, Want: []string{"日", "本", "語", "で", "す"}},
+	}
+	for _, d := range data {
+		var got []string
+		for _, line := range appendWrap(nil, []byte(d.Value), d.Width) {
+			got = append(got, string(line))
+		}
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", d.Want) {
+			t.Errorf("%q(%d): want %q, got %q", d.Value, d.Width, d.Want, got)
+		}
+	}
+}
+
+func TestTableUnits(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		tb  = NewTable(NewWriter(64, WithSeparator([]byte("|"))), WithHeaders("clock", "rate", "link"))
+	)
+	tb.AppendUnit(2.4e9, "Hz", 1, AlignRight)
+	tb.AppendRate(3<<20, 2*time.Second, AlignRight)
+	tb.AppendRate(12300000, time.Second, AlignRight|SizeBits)
+	tb.EndRow()
+	tb.AppendUnit(0.0033, "V", 1, AlignRight)
+	tb.AppendRate(0, 0, AlignRight)
+	tb.AppendRate(1, 0, AlignRight)
+	tb.EndRow()
+	tb.WriteTo(&buf)
+
+	want := "  clock|     rate|       link\n2.4 GHz|1.5 MiB/s|98.4 Mbit/s\n 3.3 mV|    0 B/s|          -\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestTableWrapNumber(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		tb  = NewTable(NewWriter(64, WithSeparator([]byte("|"))), WithWrap(), WithMaxWidth(3, 3))
+	)
+	tb.AppendInt(123456, AlignRight)
+	tb.AppendString("abcdef", AlignLeft)
+	tb.EndRow()
+	tb.WriteTo(&buf)
+
+	want := "123456|abc\n      |def\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestTableRead(t *testing.T) {
+	tb := NewTable(NewWriter(256, WithSeparator([]byte("|"))), WithMaxWidth(5), WithWrap())
+	tb.AppendString("hello world", AlignLeft)
+	tb.AppendInt(-1, AlignRight)
+	tb.EndRow()
+	tb.AppendString("x", AlignLeft)
+	tb.AppendInt(10, AlignRight)
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, struct{ io.Reader }{tb}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "hello|-1\nworld|  \nx    |10\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if n, err := tb.Read(make([]byte, 16)); n != 0 || err != io.EOF {
+		t.Errorf("want EOF, got %d, %v", n, err)
+	}
+}
+
+func TestTableLocale(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		w   = NewWriter(64, WithSeparator([]byte("|")), WithLocale(French), WithSizePrecision(1))
+		tb  = NewTable(w)
+	)
+	tb.AppendFloat(1234.5, 1, AlignRight|Float|WithGrouping)
+	tb.AppendBool(true, AlignLeft|YesNo)
+	tb.AppendPercent(0.125, 1, AlignRight)
+	tb.AppendSize(1234567, AlignRight|SizeIEC)
+	tb.EndRow()
+	tb.WriteTo(&buf)
+
+	want := "1\u202f234,5|oui|12,5\u00a0%|1,2M\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	buf.Reset()
+	w = NewWriter(64, WithGroupSeparator("_", 4))
+	tb = NewTable(w)
+	tb.AppendInt(12345678, AlignRight|WithGrouping)
+	tb.EndRow()
+	tb.WriteTo(&buf)
+	if want, got := "1234_5678\n", buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestTableAllocs(t *testing.T) {
+	data := []struct {
+		Name    string
+		Writer  []Option
+		Options []TableOption
+	}{
+		{Name: "plain", Writer: []Option{WithSeparator([]byte(" "))}, Options: []TableOption{WithHeaders("name", "value")}},
+		{Name: "border", Writer: []Option{WithPadding([]byte(" "))}, Options: []TableOption{WithHeaders("name", "value"), WithBorder(BorderBox)}},
+		{Name: "wrap", Options: []TableOption{WithWrap(), WithMaxWidth(6)}},
+		{Name: "markdown", Writer: []Option{AsMarkdown()}, Options: []TableOption{WithHeaders("name", "value")}},
+	}
+	for _, d := range data {
+		var (
+			tb  = NewTable(NewWriter(128, d.Writer...), d.Options...)
+			buf = make([]byte, 1024)
+		)
+		n := testing.AllocsPerRun(100, func() {
+			tb.AppendString("hello world\nagain", AlignLeft)
+			tb.AppendInt(42, AlignRight)
+			tb.EndRow()
+			tb.AppendString("playback", AlignLeft)
+			tb.AppendFloat(3.14, 2, AlignRight)
+			tb.EndRow()
+			tb.WriteTo(io.Discard)
+
+			tb.AppendString("playback", AlignLeft)
+			tb.EndRow()
+			for {
+				if _, err := tb.Read(buf); err != nil {
+					break
+				}
+			}
+		})
+		if n != 0 {
+			t.Errorf("%s: want no allocation, got %.1f", d.Name, n)
+		}
+	}
+}
+
+func BenchmarkTable(b *testing.B) {
+	tb := NewTable(NewWriter(128, WithPadding([]byte(" "))), WithHeaders("name", "count", "ratio"), WithBorder(BorderBox))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 10; j++ {
+			tb.AppendString("playback", AlignLeft)
+			tb.AppendInt(int64(j), AlignRight)
+			tb.AppendPercent(0.25, 1, AlignRight)
+			tb.EndRow()
+		}
+		tb.WriteTo(io.Discard)
+	}
+}
@@ -0,0 +1,232 @@
+package linewriter
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"time"
+)
+
+var siPrefixes = []string{
+	"q", "r", "y", "z", "a", "f", "p", "n", "µ", "m",
+	"",
+	"k", "M", "G", "T", "P", "E", "Z", "Y", "R", "Q",
+}
+
+var iecPrefixes = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei", "Zi", "Yi"}
+
+func siPrefix(exp int) (string, bool) {
+	if exp%3 != 0 {
+		return "", false
+	}
+	i := exp/3 + len(siPrefixes)/2
+	if i < 0 || i >= len(siPrefixes) {
+		return "", false
+	}
+	return siPrefixes[i], true
+}
+
+func (w *Writer) AppendUnit(v float64, unit string, width, prec int, flag Flag) {
+	w.appendLeft(flag)
+	w.formatUnit(v, unit, prec, true, flag)
+	w.appendNumber(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
+}
+
+func (w *Writer) AppendRate(bytes int64, d time.Duration, width int, flag Flag) {
+	w.appendLeft(flag)
+	flag = w.checkFlag(flag, sizeFlags)
+
+	var (
+		unit = "B/s"
+		iec  = isSizeIEC(w.flags, flag)
+		v    = float64(bytes)
+	)
+	if set := flag & SizeBits; set != 0 {
+		unit, iec = "bit/s", flag&SizeIEC != 0
+		v *= 8
+	}
+	if flag &^= SizeIEC; iec {
+		flag |= SizeIEC
+	}
+	switch {
+	case bytes == 0:
+		w.formatUnit(0, unit, w.sizePrecision(1), false, flag)
+	case d <= 0:
+		w.tmp = append(w.tmp, '-')
+	default:
+		w.formatUnit(v/d.Seconds(), unit, w.sizePrecision(1), false, flag)
+	}
+	w.appendNumber(w.tmp, width, flag)
+	w.tmp = w.tmp[:0]
+}
+
+// formatUnit scales v to the prefix of its magnitude. Values below the base
+// unit only get a submultiple prefix when sub is set, and values out of the
+// range of the SI prefixes are written with an exponent instead.
+func (w *Writer) formatUnit(v float64, unit string, prec int, sub bool, flag Flag) {
+	var (
+		prefix string
+		exp    int
+		ok     = true
+	)
+	if v != 0 && !math.IsInf(v, 0) && !math.IsNaN(v) {
+		if set := flag & SizeIEC; set != 0 && math.Abs(v) >= 1 {
+			v, prefix = scaleIEC(v, prec)
+		} else {
+			v, exp = scaleSI(v, prec, sub)
+			prefix, ok = siPrefix(exp)
+		}
+	}
+	w.tmp = strconv.AppendFloat(w.tmp, v, 'f', prec, 64)
+	w.adjustFloat(flag)
+	if !ok {
+		w.appendExponent(exp)
+	}
+	if prefix == "" && unit == "" {
+		return
+	}
+	w.tmp = append(w.tmp, ' ')
+	w.tmp = append(w.tmp, prefix...)
+	w.tmp = append(w.tmp, unit...)
+}
+
+func scaleSI(v float64, prec int, sub bool) (float64, int) {
+	var (
+		exp = exponent(v)
+		eng = exp - ((exp%3)+3)%3
+	)
+	if eng < 0 && !sub {
+		eng = 0
+	}
+	mant := scale(v, eng)
+	if roundsTo(mant, prec, 1000) {
+		eng += 3
+		mant = scale(v, eng)
+	}
+	return mant, eng
+}
+
+func scaleIEC(v float64, prec int) (float64, string) {
+	var i int
+	for i < len(iecPrefixes)-1 && math.Abs(v) >= 1024 {
+		v /= 1024
+		i++
+	}
+	if i < len(iecPrefixes)-1 && roundsTo(v, prec, 1024) {
+		v /= 1024
+		i++
+	}
+	return v, iecPrefixes[i]
+}
+
+func (w *Writer) formatSignificant(v float64, format byte, prec int, flag Flag) {
+	switch {
+	case prec <= 0 || math.IsInf(v, 0) || math.IsNaN(v):
+		w.tmp = strconv.AppendFloat(w.tmp, v, format, -1, 64)
+	case format == 'e':
+		w.tmp = strconv.AppendFloat(w.tmp, v, 'e', prec-1, 64)
+	case format == 'f':
+		dec := prec - 1
+		if v != 0 {
+			dec -= w.roundedExponent(v, prec)
+		}
+		if dec < 0 {
+			p := math.Pow10(-dec)
+			v, dec = math.Round(v/p)*p, 0
+		}
+		w.tmp = strconv.AppendFloat(w.tmp, v, 'f', dec, 64)
+	default:
+		w.tmp = strconv.AppendFloat(w.tmp, v, 'g', prec, 64)
+	}
+	w.adjustFloat(flag)
+}
+
+func (w *Writer) formatEngineering(v float64, prec int, flag Flag) {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		w.tmp = strconv.AppendFloat(w.tmp, v, 'f', prec, 64)
+		w.adjustFloat(flag)
+		return
+	}
+	exp := exponent(v)
+	if set := flag & Significant; set != 0 && prec > 0 {
+		exp = w.roundedExponent(v, prec)
+	}
+	var (
+		eng    = exp - ((exp%3)+3)%3
+		digits = prec
+		mant   = scale(v, eng)
+	)
+	if set := flag & Significant; set != 0 && prec > 0 {
+		if digits = prec - 1 - (exp - eng); digits < 0 {
+			p := math.Pow10(-digits)
+			mant, digits = math.Round(mant/p)*p, 0
+		}
+	}
+	if roundsTo(mant, digits, 1000) {
+		eng += 3
+		mant = scale(v, eng)
+	}
+	w.tmp = strconv.AppendFloat(w.tmp, mant, 'f', digits, 64)
+	w.adjustFloat(flag)
+
+	if prefix, ok := siPrefix(eng); ok && flag&SIPrefix != 0 {
+		w.tmp = append(w.tmp, prefix...)
+		return
+	}
+	w.appendExponent(eng)
+}
+
+func (w *Writer) appendExponent(exp int) {
+	w.tmp = append(w.tmp, 'e')
+	if exp < 0 {
+		w.tmp, exp = append(w.tmp, '-'), -exp
+	} else {
+		w.tmp = append(w.tmp, '+')
+	}
+	if exp < 10 {
+		w.tmp = append(w.tmp, '0')
+	}
+	w.tmp = strconv.AppendInt(w.tmp, int64(exp), 10)
+}
+
+func roundsTo(v float64, digits int, limit float64) bool {
+	if digits < 0 {
+		return math.Abs(v) >= limit
+	}
+	p := math.Pow10(digits)
+	return math.Abs(math.Round(v*p)/p) >= limit
+}
+
+func exponent(v float64) int {
+	if v != 0 && math.Abs(v) < 1e-300 {
+		return exponent(v*1e300) - 300
+	}
+	return int(math.Floor(math.Log10(math.Abs(v))))
+}
+
+func (w *Writer) roundedExponent(v float64, prec int) int {
+	w.scratch = strconv.AppendFloat(w.scratch[:0], v, 'e', prec-1, 64)
+	var (
+		i   = bytes.LastIndexByte(w.scratch, 'e') + 1
+		neg = w.scratch[i] == '-'
+		exp int
+	)
+	for _, c := range w.scratch[i+1:] {
+		exp = exp*10 + int(c-'0')
+	}
+	if neg {
+		exp = -exp
+	}
+	return exp
+}
+
+func scale(v float64, exp int) float64 {
+	if exp < 0 {
+		if exp < -300 {
+			v, exp = v*1e300, exp+300
+		}
+		return v * math.Pow10(-exp)
+	}
+	return v / math.Pow10(exp)
+}
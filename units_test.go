@@ -0,0 +1,202 @@
+package linewriter
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func ExampleWriter_AppendUnit() {
+	w := NewWriter(64, WithPadding([]byte(" ")), WithSeparator([]byte("|")))
+	w.AppendUnit(2.4e9, "Hz", 8, 1, AlignRight)
+	w.AppendUnit(0.0033, "V", 8, 1, AlignRight)
+	w.AppendUnit(1536, "B", 8, 1, AlignRight|SizeIEC)
+
+	fmt.Println(w.String())
+	// Output:
+	// 2.4 GHz |   3.3 mV |  1.5 KiB
+}
+
+func TestAppendUnit(t *testing.T) {
+	data := []struct {
+		Value float64
+		Unit  string
+		Prec  int
+		Flags Flag
+		Want  string
+	}{
+		{Value: 0, Unit: "Hz", Prec: 1, Want: "0 Hz"},
+		{Value: 50, Unit: "Hz", Prec: 1, Want: "50 Hz"},
+		{Value: 2.4e9, Unit: "Hz", Prec: 1, Want: "2.4 GHz"},
+		{Value: 2.4e9, Unit: "Hz", Prec: 2, Flags: WithZero, Want: "2.40 GHz"},
+		{Value: 999.96, Unit: "W", Prec: 1, Want: "1 kW"},
+		{Value: -1500, Unit: "W", Prec: 1, Want: "-1.5 kW"},
+		{Value: 0.0033, Unit: "V", Prec: 1, Want: "3.3 mV"},
+		{Value: 0.0000047, Unit: "F", Prec: 1, Want: "4.7 µF"},
+		{Value: 0.000000012, Unit: "s", Prec: 0, Want: "12 ns"},
+		{Value: 0.25, Unit: "m", Prec: 0, Want: "250 mm"},
+		{Value: 1e40, Unit: "bit", Prec: 0, Want: "10e+39 bit"},
+		{Value: 999.96e30, Unit: "W", Prec: 1, Want: "1e+33 W"},
+		{Value: 1.5e-40, Unit: "W", Prec: 1, Want: "150e-42 W"},
+		{Value: 1.5e-40, Prec: 1, Want: "150e-42"},
+		{Value: 98.4e6, Unit: "bit", Prec: 1, Want: "98.4 Mbit"},
+		{Value: 1536, Unit: "B", Prec: 1, Flags: SizeIEC, Want: "1.5 KiB"},
+		{Value: 1536, Unit: "B", Prec: 1, Flags: SizeSI, Want: "1.5 kB"},
+		{Value: 1023.99, Unit: "B", Prec: 1, Flags: SizeIEC, Want: "1 KiB"},
+		{Value: 0.5, Unit: "B", Prec: 1, Flags: SizeIEC, Want: "500 mB"},
+		{Value: 3 << 30, Unit: "bit", Prec: 0, Flags: SizeIEC, Want: "3 Gibit"},
+		{Value: 4700, Prec: 1, Want: "4.7 k"},
+		{Value: 47, Prec: 1, Want: "47"},
+		{Value: math.Inf(-1), Unit: "V", Prec: 1, Want: "-Inf V"},
+	}
+	for i, d := range data {
+		w := NewWriter(256, WithFlag(DefaultFlags|NoPadding))
+		w.AppendUnit(d.Value, d.Unit, 0, d.Prec, d.Flags)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func TestEngineering(t *testing.T) {
+	data := []struct {
+		Value float64
+		Prec  int
+		Flags Flag
+		Want  string
+	}{
+		{Value: 4700, Prec: 2, Flags: Engineering, Want: "4.7e+03"},
+		{Value: 4700, Prec: 2, Flags: Engineering | WithZero, Want: "4.70e+03"},
+		{Value: 47000, Prec: 1, Flags: Engineering, Want: "47e+03"},
+		{Value: 470000, Prec: -1, Flags: Engineering, Want: "470e+03"},
+		{Value: 4.7, Prec: 2, Flags: Engineering, Want: "4.7e+00"},
+		{Value: 0.0123, Prec: 1, Flags: Engineering, Want: "12.3e-03"},
+		{Value: -0.0000123, Prec: 2, Flags: Engineering, Want: "-12.3e-06"},
+		{Value: 999.96, Prec: 1, Flags: Engineering, Want: "1e+03"},
+		{Value: 1.5e-30, Prec: 1, Flags: Engineering, Want: "1.5e-30"},
+		{Value: 4700, Prec: 2, Flags: Engineering | SIPrefix, Want: "4.7k"},
+		{Value: 0.0000123, Prec: 2, Flags: Engineering | SIPrefix, Want: "12.3µ"},
+		{Value: 2.2e-9, Prec: 2, Flags: Engineering | SIPrefix, Want: "2.2n"},
+		{Value: 1.21e9, Prec: 2, Flags: Engineering | SIPrefix, Want: "1.21G"},
+		{Value: 12, Prec: 2, Flags: Engineering | SIPrefix, Want: "12"},
+		{Value: 1e33, Prec: 2, Flags: Engineering | SIPrefix, Want: "1e+33"},
+		{Value: 0, Prec: 2, Flags: Engineering | SIPrefix, Want: "0"},
+		{Value: 123456, Prec: 3, Flags: Engineering | Significant | SIPrefix, Want: "123k"},
+		{Value: 1234.56, Prec: 3, Flags: Engineering | Significant | SIPrefix, Want: "1.23k"},
+		{Value: 0.000123456, Prec: 2, Flags: Engineering | Significant | SIPrefix, Want: "120µ"},
+		{Value: 0.5, Prec: 1, Flags: Engineering | SIPrefix | Percent, Want: "500m%"},
+		{Value: math.Inf(1), Prec: 2, Flags: Engineering, Want: "+Inf"},
+		{Value: 5e-324, Prec: 2, Flags: Engineering, Want: "4.94e-324"},
+		{Value: 1e-310, Prec: 2, Flags: Engineering, Want: "100e-312"},
+		{Value: -1e-310, Prec: 2, Flags: Engineering | SIPrefix, Want: "-100e-312"},
+		{Value: 9.9999, Prec: 2, Flags: Engineering | Significant | WithZero, Want: "10e+00"},
+		{Value: 999.96, Prec: 2, Flags: Engineering | Significant | WithZero | SIPrefix, Want: "1.0k"},
+	}
+	for i, d := range data {
+		w := NewWriter(256, WithFlag(DefaultFlags|NoPadding))
+		w.AppendFloat(d.Value, 0, d.Prec, d.Flags)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func TestSignificant(t *testing.T) {
+	data := []struct {
+		Value float64
+		Prec  int
+		Flags Flag
+		Want  string
+	}{
+		{Value: 123.456, Prec: 2, Flags: Float | Significant, Want: "120"},
+		{Value: 123.456, Prec: 4, Flags: Float | Significant, Want: "123.5"},
+		{Value: 0.000123456, Prec: 3, Flags: Float | Significant, Want: "0.000123"},
+		{Value: 0.000123456, Prec: 3, Flags: Float | Significant | WithZero, Want: "0.000123"},
+		{Value: 1.5, Prec: 4, Flags: Float | Significant | WithZero, Want: "1.500"},
+		{Value: 1.5, Prec: 4, Flags: Float | Significant, Want: "1.5"},
+		{Value: 123456789, Prec: 3, Flags: Float | Significant, Want: "123000000"},
+		{Value: -98765, Prec: 2, Flags: Float | Significant, Want: "-99000"},
+		{Value: 0, Prec: 3, Flags: Float | Significant | WithZero, Want: "0.00"},
+		{Value: 123456, Prec: 3, Flags: Scientific | Significant, Want: "1.23e+05"},
+		{Value: 123456, Prec: 3, Flags: Significant, Want: "1.23e+05"},
+		{Value: 0.5, Prec: 3, Flags: Significant | Percent | Float, Want: "0.5%"},
+		{Value: 123.456, Prec: 0, Flags: Float | Significant, Want: "123.456"},
+		{Value: 9.9999, Prec: 2, Flags: Float | Significant | WithZero, Want: "10"},
+		{Value: 9.9999, Prec: 3, Flags: Float | Significant | WithZero, Want: "10.0"},
+		{Value: -0.099996, Prec: 3, Flags: Float | Significant | WithZero, Want: "-0.100"},
+		{Value: 99999, Prec: 2, Flags: Float | Significant, Want: "100000"},
+		{Value: 5e-324, Prec: 1, Flags: Scientific | Significant, Want: "5e-324"},
+	}
+	for i, d := range data {
+		w := NewWriter(256, WithFlag(DefaultFlags|NoPadding))
+		w.AppendFloat(d.Value, 0, d.Prec, d.Flags)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func TestAppendRate(t *testing.T) {
+	data := []struct {
+		Bytes    int64
+		Duration time.Duration
+		Flags    Flag
+		Options  []Option
+		Want     string
+	}{
+		{Bytes: 0, Duration: time.Second, Want: "0 B/s"},
+		{Bytes: 0, Duration: 0, Want: "0 B/s"},
+		{Bytes: 1024, Duration: 0, Want: "-"},
+		{Bytes: 1024, Duration: -time.Second, Want: "-"},
+		{Bytes: 512, Duration: time.Second, Want: "512 B/s"},
+		{Bytes: 12900000, Duration: time.Second, Want: "12.3 MiB/s"},
+		{Bytes: 12900000, Duration: time.Second, Flags: SizeSI, Want: "12.9 MB/s"},
+		{Bytes: 12900000, Duration: time.Second, Flags: DefaultFlags | SizeSI, Want: "12.9 MB/s"},
+		{Bytes: 12900000, Duration: time.Second, Options: []Option{WithFlag(DefaultFlags | SizeSI)}, Want: "12.9 MB/s"},
+		{Bytes: 12900000, Duration: time.Second, Flags: SizeSI, Options: []Option{WithFlag(NoPadding)}, Want: "12.9 MB/s"},
+		{Bytes: 12300000, Duration: time.Second, Flags: SizeBits, Want: "98.4 Mbit/s"},
+		{Bytes: 12300000, Duration: time.Second, Flags: SizeBits | SizeIEC, Want: "93.8 Mibit/s"},
+		{Bytes: 3 << 20, Duration: 2 * time.Second, Want: "1.5 MiB/s"},
+		{Bytes: 3 << 20, Duration: 2 * time.Second, Options: []Option{WithSizePrecision(3)}, Want: "1.5 MiB/s"},
+		{Bytes: 3 << 20, Duration: 2 * time.Second, Flags: WithZero, Options: []Option{WithSizePrecision(3)}, Want: "1.500 MiB/s"},
+		{Bytes: 1, Duration: time.Minute, Flags: SizeBits, Want: "0.1 bit/s"},
+		{Bytes: 1, Duration: time.Hour, Want: "0 B/s"},
+		{Bytes: 1, Duration: time.Hour, Flags: SizeSI, Options: []Option{WithSizePrecision(4)}, Want: "0.0003 B/s"},
+		{Bytes: 999, Duration: 999 * time.Millisecond, Flags: SizeSI, Want: "1 kB/s"},
+	}
+	for i, d := range data {
+		options := append([]Option{WithFlag(DefaultFlags | NoPadding)}, d.Options...)
+		w := NewWriter(256, options...)
+		w.AppendRate(d.Bytes, d.Duration, 0, d.Flags)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%d: want %q, got %q", i+1, d.Want, got)
+		}
+	}
+}
+
+func FuzzAppendUnit(f *testing.F) {
+	f.Add(2.4e9, "Hz", 8, 1, uint64(AlignRight))
+	f.Add(-0.0000047, "F", 4, 3, uint64(AlignLeft|WithZero))
+	f.Add(math.Inf(1), "", 0, -1, uint64(SizeIEC))
+	f.Add(1e300, "bit", 2, 64, uint64(SizeIEC|WithGrouping))
+	f.Fuzz(func(t *testing.T, v float64, unit string, width, prec int, flag uint64) {
+		if prec = prec % 32; prec < -1 {
+			prec = -1
+		}
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendUnit(v, unit, width, prec, flag)
+		})
+	})
+}
+
+func FuzzAppendRate(f *testing.F) {
+	f.Add(int64(12900000), int64(time.Second), 12, uint64(AlignRight))
+	f.Add(int64(1), int64(0), 1, uint64(SizeBits))
+	f.Add(int64(math.MinInt64), int64(1), 0, uint64(SizeBits|SizeIEC|WithZero))
+	f.Fuzz(func(t *testing.T, bytes, d int64, width int, flag uint64) {
+		fuzzAppend(t, width, Flag(flag), func(w *Writer, width int, flag Flag) {
+			w.AppendRate(bytes, time.Duration(d), width, flag)
+		})
+	})
+}
@@ -0,0 +1,156 @@
+package linewriter
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zwj      = 0x200D
+	vs15     = 0xFE0E
+	vs16     = 0xFE0F
+	riFirst  = 0x1F1E6
+	riLast   = 0x1F1FF
+	modFirst = 0x1F3FB
+	modLast  = 0x1F3FF
+)
+
+type interval struct {
+	first rune
+	last  rune
+}
+
+var wide = []interval{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4},
+	{0x17000, 0x18CFF}, {0x1B000, 0x1B2FF}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F251}, {0x1F300, 0x1F320},
+	{0x1F32D, 0x1F335}, {0x1F337, 0x1F37C}, {0x1F37E, 0x1F393}, {0x1F3A0, 0x1F3CA},
+	{0x1F3CF, 0x1F3D3}, {0x1F3E0, 0x1F3F0}, {0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E},
+	{0x1F440, 0x1F440}, {0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D}, {0x1F54B, 0x1F54E},
+	{0x1F550, 0x1F567}, {0x1F57A, 0x1F57A}, {0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4},
+	{0x1F5FB, 0x1F64F}, {0x1F680, 0x1F6C5}, {0x1F6CC, 0x1F6CC}, {0x1F6D0, 0x1F6D2},
+	{0x1F6D5, 0x1F6D7}, {0x1F6DC, 0x1F6DF}, {0x1F6EB, 0x1F6EC}, {0x1F6F4, 0x1F6FC},
+	{0x1F7E0, 0x1F7EB}, {0x1F7F0, 0x1F7F0}, {0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945},
+	{0x1F947, 0x1F9FF}, {0x1FA70, 0x1FAFF}, {0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+func displayWidth(data []byte) int {
+	var width int
+	for len(data) > 0 {
+		n, w := nextCluster(data)
+		width += w
+		data = data[n:]
+	}
+	return width
+}
+
+func cut(data []byte, width int) []byte {
+	var i int
+	for i < len(data) {
+		n, z := nextCluster(data[i:])
+		if width -= z; width < 0 {
+			break
+		}
+		i += n
+	}
+	return data[:i]
+}
+
+func cutLeft(data []byte, width int) []byte {
+	var i int
+	for n := displayWidth(data); n > width && i < len(data); {
+		c, z := nextCluster(data[i:])
+		n -= z
+		i += c
+	}
+	return data[i:]
+}
+
+func nextCluster(data []byte) (int, int) {
+	r, n := utf8.DecodeRune(data)
+	var (
+		width    = runeWidth(r)
+		regional = isRegional(r)
+	)
+	for n < len(data) {
+		next, z := utf8.DecodeRune(data[n:])
+		switch {
+		case next == zwj:
+			if n+z < len(data) {
+				_, j := utf8.DecodeRune(data[n+z:])
+				z += j
+			}
+		case next == vs16:
+			if width == 1 {
+				width = 2
+			}
+		case next == vs15:
+		case next >= modFirst && next <= modLast:
+		case regional && isRegional(next):
+			regional = false
+		case isControl(next) || runeWidth(next) != 0:
+			return n, width
+		}
+		n += z
+	}
+	return n, width
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case isControl(r):
+		return 0
+	case r < 0x300:
+		return 1
+	case r >= 0x1160 && r <= 0x11FF:
+		return 0
+	case r == 0x200B:
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isRegional(r):
+		return 2
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+func isWide(r rune) bool {
+	if r < wide[0].first {
+		return false
+	}
+	lo, hi := 0, len(wide)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wide[mid].first:
+			hi = mid - 1
+		case r > wide[mid].last:
+			lo = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func isRegional(r rune) bool {
+	return r >= riFirst && r <= riLast
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || (r >= 0x7F && r < 0xA0)
+}
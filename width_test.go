@@ -0,0 +1,59 @@
+package linewriter
+
+import (
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	data := []struct {
+		Value string
+		Want  int
+	}{
+		{Value: "", Want: 0},
+		{Value: "playback", Want: 8},
+		{Value: "café", Want: 4},
+		{Value: "café", Want: 4},
+		{Value: "日本語", Want: 6},
+		{Value: "ｱｲｳ", Want: 3},
+		{Value: "ＡＢＣ", Want: 6},
+		{Value: "한국어", Want: 6},
+		{Value: "각", Want: 2},
+		{Value: "a\u200bb", Want: 2},
+		{Value: "🚀", Want: 2},
+		{Value: "❤", Want: 1},
+		{Value: "❤️", Want: 2},
+		{Value: "👍🏽", Want: 2},
+		{Value: "👩‍💻", Want: 2},
+		{Value: "👨‍👩‍👧‍👦", Want: 2},
+		{Value: "🇫🇷🇯🇵", Want: 4},
+		{Value: "x\tz", Want: 2},
+		{Value: "\xff", Want: 1},
+	}
+	for _, d := range data {
+		if got := displayWidth([]byte(d.Value)); got != d.Want {
+			t.Errorf("%q: want %d, got %d", d.Value, d.Want, got)
+		}
+	}
+}
+
+func TestAlignWidth(t *testing.T) {
+	data := []struct {
+		Value string
+		Flags Flag
+		Want  string
+	}{
+		{Value: "日本語", Flags: AlignLeft, Want: "_日本語    _"},
+		{Value: "日本語", Flags: AlignRight, Want: "_    日本語_"},
+		{Value: "日本語", Flags: AlignCenter, Want: "_  日本語  _"},
+		{Value: "👩‍💻 dev", Flags: AlignRight, Want: "_    👩‍💻 dev_"},
+		{Value: "café", Flags: AlignRight, Want: "_      café_"},
+		{Value: "日本語日本語", Flags: AlignRight, Want: "_日本語日本語_"},
+	}
+	for _, d := range data {
+		w := NewWriter(256, defaults...)
+		w.AppendString(d.Value, 10, d.Flags)
+		if got := w.String(); got != d.Want {
+			t.Errorf("%q: want %q, got %q", d.Value, d.Want, got)
+		}
+	}
+}